@@ -67,7 +67,7 @@ func main() {
 			// std
 			logFile,
 			"", log.LstdFlags),
-	}, 4, 4)
+	}, scraper.MaxConcurrentEndpoints(), scraper.MaxConcurrentEndpoints())
 
 	e := echo.New()
 
@@ -96,6 +96,8 @@ func main() {
 	scrape.GET("/results/not-empty/:groupId", handlers.GetScrapingResultsNotEmpty)
 	scrape.POST("/results/export/:groupId", handlers.ExportGroupResultsHandler)
 	scrape.POST("/endpoints", handlers.ScrapeEndpointsHandler)
+	scrape.POST("/cancel", handlers.CancelScrapeHandler)
+	scrape.POST("/replay", handlers.ReplayScrapeHandler)
 	scrape.POST("/endpoint-test", handlers.ScrapeEndpointTestHandler)
 
 	// Scrape groups routes
@@ -110,6 +112,7 @@ func main() {
 	groups.GET("/version-tag-exists/:versionTag", handlers.VersionTagExists)
 	groups.GET("/:id/notification-config", handlers.GetScrapingGroupNotificationConfig)
 	groups.PUT("/:id/notification-config", handlers.ChangeScrapingGroupNotificationConfig)
+	groups.PUT("/:id/robots-txt", handlers.UpdateScrapingGroupRobotsTxt)
 
 	// Endpoints within scrape groups
 	groups.POST("/:groupId/endpoints", handlers.CreateScrapingGroupEndpoint)
@@ -122,6 +125,32 @@ func main() {
 	selectors.POST("/extract", handlers.ExtractSelectorsHandler)
 	selectors.POST("/test", handlers.ElementSelectorTestHandler)
 
+	// Proxy pool routes
+	proxyPools := api.Group("/proxy-pools")
+	proxyPools.GET("", handlers.GetProxyPools)
+	proxyPools.POST("", handlers.CreateProxyPool)
+	proxyPools.PUT("/:id", handlers.UpdateProxyPool)
+	proxyPools.DELETE("/:id", handlers.DeleteProxyPool)
+	proxyPools.POST("/:id/check", handlers.CheckProxyPool)
+
+	// Screenshots and html of pages that failed during scrapes
+	pageFailures := api.Group("/page-failures")
+	pageFailures.GET("", handlers.GetPageFailures)
+	pageFailures.GET("/:id", handlers.GetPageFailure)
+	pageFailures.GET("/:id/screenshot", handlers.GetPageFailureScreenshot)
+	pageFailures.GET("/:id/html", handlers.GetPageFailureHTML)
+	pageFailures.DELETE("/:id", handlers.DeletePageFailure)
+
+	// Stored challenge cookies and login sessions
+	cookieSessions := api.Group("/cookie-sessions")
+	cookieSessions.GET("", handlers.GetCookieSessions)
+	cookieSessions.DELETE("", handlers.DeleteCookieSessions)
+	cookieSessions.GET("/ttls", handlers.GetCookieTTLRules)
+	cookieSessions.PUT("/ttls/:domain", handlers.UpdateCookieTTLRule)
+	cookieSessions.DELETE("/ttls/:domain", handlers.DeleteCookieTTLRule)
+	cookieSessions.GET("/:key", handlers.GetCookieSession)
+	cookieSessions.DELETE("/:key", handlers.DeleteCookieSession)
+
 	ai := api.Group("/ai")
 	ai.POST("/completion", handlers.CompletionHandler)
 	fmt.Println("Starting server on port 3457")
@@ -3,7 +3,7 @@ package export
 import (
 	"bytes"
 	"encoding/csv"
-	"fmt"
+	"scrapeit/internal/helpers"
 	"scrapeit/internal/models"
 )
 
@@ -30,7 +30,7 @@ func getCsvBytes(input []models.ExportScrapeResult) ([]byte, error) {
 		row := make([]string, len(headers))
 		for i, header := range headers {
 			if value, ok := record[header]; ok {
-				row[i] = fmt.Sprintf("%v", value)
+				row[i] = helpers.FormatFieldValue(value)
 			} else {
 				row[i] = ""
 			}
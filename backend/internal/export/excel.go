@@ -2,6 +2,7 @@ package export
 
 import (
 	"fmt"
+	"scrapeit/internal/helpers"
 	"scrapeit/internal/models"
 
 	"github.com/xuri/excelize/v2"
@@ -24,7 +25,7 @@ func getExcelBytes(input []models.ExportScrapeResult) ([]byte, error) {
 	for _, record := range records {
 		row := []interface{}{}
 		for _, key := range headers {
-			row = append(row, fmt.Sprintf("%v", record[key]))
+			row = append(row, helpers.FormatFieldValue(record[key]))
 		}
 		rows = append(rows, row)
 	}
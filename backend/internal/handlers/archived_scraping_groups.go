@@ -30,5 +30,8 @@ func GetArchivedScrapingGroups(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	for i := range allGroups {
+		allGroups[i].RedactSecrets()
+	}
 	return c.JSON(http.StatusOK, allGroups)
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CancelScrapeRequest struct {
+	GroupId     string   `json:"groupId"`
+	EndpointIds []string `json:"endpointIds"`
+}
+
+type CancelScrapeResponse struct {
+	Cancelled []string `json:"cancelled"`
+	Reset     []string `json:"reset"`
+}
+
+// CancelScrapeHandler cancels the running scrapes of the endpoints. A
+// cancelled scrape closes its pages, stores the results it already scraped
+// and sets its endpoint back to idle. Endpoints marked as running without a
+// scrape in progress are set to idle right away.
+func CancelScrapeHandler(c echo.Context) error {
+	var body CancelScrapeRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	// getScrapeGroup has already written the response when it returns no
+	// group.
+	group, err := getScrapeGroup(c, dbClient, body.GroupId)
+	if group == nil {
+		return err
+	}
+
+	endpointIds := body.EndpointIds
+	if len(endpointIds) == 0 {
+		for _, endpoint := range group.Endpoints {
+			endpointIds = append(endpointIds, endpoint.ID)
+		}
+	}
+
+	response := CancelScrapeResponse{Cancelled: []string{}, Reset: []string{}}
+	var stale []*models.Endpoint
+	for _, endpointId := range endpointIds {
+		endpoint := group.GetEndpointById(endpointId)
+		if endpoint == nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": fmt.Sprintf("Endpoint %s not found", endpointId)})
+		}
+		if scraper.CancelRun(body.GroupId, endpointId) {
+			response.Cancelled = append(response.Cancelled, endpointId)
+		} else if endpoint.Status == models.ScrapeStatusRunning {
+			stale = append(stale, endpoint)
+			response.Reset = append(response.Reset, endpointId)
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := updateEndpointStatuses(dbClient, group.ID, stale, models.ScrapeStatusIdle); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
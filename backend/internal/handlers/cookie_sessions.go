@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CookieSessionSummary describes a stored session without its cookie values.
+type CookieSessionSummary struct {
+	Key         string                   `json:"key"`
+	Kind        models.CookieSessionKind `json:"kind"`
+	Domain      string                   `json:"domain"`
+	CookieCount int                      `json:"cookieCount"`
+	Updated     time.Time                `json:"updated"`
+	ExpiresAt   *time.Time               `json:"expiresAt,omitempty"`
+	Valid       bool                     `json:"valid"`
+}
+
+// CookieSummary describes a stored cookie without its value.
+type CookieSummary struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	Expiry int64  `json:"expiry"`
+}
+
+// CookieSessionDetail describes a stored session and its cookies without
+// the cookie values, which work as credentials for the site.
+type CookieSessionDetail struct {
+	Key       string                   `json:"key"`
+	Kind      models.CookieSessionKind `json:"kind"`
+	Domain    string                   `json:"domain"`
+	Cookies   []CookieSummary          `json:"cookies"`
+	UserAgent string                   `json:"userAgent,omitempty"`
+	Updated   time.Time                `json:"updated"`
+	ExpiresAt *time.Time               `json:"expiresAt,omitempty"`
+	Valid     bool                     `json:"valid"`
+}
+
+// GetCookieSessions lists the stored challenge cookies and login sessions,
+// optionally filtered by kind and domain.
+func GetCookieSessions(c echo.Context) error {
+	filter := bson.M{}
+	if kind := c.QueryParam("kind"); kind != "" {
+		filter["kind"] = kind
+	}
+	if domain := c.QueryParam("domain"); domain != "" {
+		filter["domain"] = domain
+	}
+
+	dbClient, _ := models.GetDbClient()
+	result, err := dbClient.Database("scrapeit").Collection("cookie_sessions").Find(c.Request().Context(), filter, options.Find().SetSort(bson.M{"updated": -1}))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer result.Close(c.Request().Context())
+
+	var sessions []models.CookieSession
+	if err := result.All(c.Request().Context(), &sessions); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	summaries := make([]CookieSessionSummary, len(sessions))
+	for i, session := range sessions {
+		summaries[i] = CookieSessionSummary{
+			Key:         session.Key,
+			Kind:        session.Kind,
+			Domain:      session.Domain,
+			CookieCount: len(session.Cookies),
+			Updated:     session.Updated,
+			ExpiresAt:   session.ExpiresAt,
+			Valid:       scraper.CookieSessionValid(session),
+		}
+	}
+	return c.JSON(http.StatusOK, summaries)
+}
+
+// GetCookieSession returns a stored session with the names, domains and
+// expiries of its cookies. The key is URL encoded in the path.
+func GetCookieSession(c echo.Context) error {
+	key, err := url.PathUnescape(c.Param("key"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid session key"})
+	}
+
+	var session models.CookieSession
+	dbClient, _ := models.GetDbClient()
+	err = dbClient.Database("scrapeit").Collection("cookie_sessions").FindOne(c.Request().Context(), bson.M{"_id": key}).Decode(&session)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
+	}
+
+	cookies := make([]CookieSummary, len(session.Cookies))
+	for i, cookie := range session.Cookies {
+		cookies[i] = CookieSummary{Name: cookie.Name, Domain: cookie.Domain, Path: cookie.Path, Expiry: cookie.Expiry}
+	}
+	return c.JSON(http.StatusOK, CookieSessionDetail{
+		Key:       session.Key,
+		Kind:      session.Kind,
+		Domain:    session.Domain,
+		Cookies:   cookies,
+		UserAgent: session.UserAgent,
+		Updated:   session.Updated,
+		ExpiresAt: session.ExpiresAt,
+		Valid:     scraper.CookieSessionValid(session),
+	})
+}
+
+// DeleteCookieSession invalidates a stored session, the next page of its
+// site solves the challenge or logs in again.
+func DeleteCookieSession(c echo.Context) error {
+	key, err := url.PathUnescape(c.Param("key"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid session key"})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	result, err := dbClient.Database("scrapeit").Collection("cookie_sessions").DeleteOne(c.Request().Context(), bson.M{"_id": key})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if result.DeletedCount == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Session not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Session invalidated successfully"})
+}
+
+// DeleteCookieSessions invalidates every stored session of a domain.
+func DeleteCookieSessions(c echo.Context) error {
+	domain := c.QueryParam("domain")
+	if domain == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "domain is required"})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	result, err := dbClient.Database("scrapeit").Collection("cookie_sessions").DeleteMany(c.Request().Context(), bson.M{"domain": domain})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"deleted": result.DeletedCount})
+}
+
+func GetCookieTTLRules(c echo.Context) error {
+	dbClient, _ := models.GetDbClient()
+	result, err := dbClient.Database("scrapeit").Collection("cookie_ttl_rules").Find(c.Request().Context(), bson.M{})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer result.Close(c.Request().Context())
+
+	rules := []models.CookieTTLRule{}
+	if err := result.All(c.Request().Context(), &rules); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, rules)
+}
+
+// UpdateCookieTTLRule sets the cookie TTL of a domain and its subdomains. It
+// applies to cookies stored from now on. A TTL of 0 keeps cookies until
+// they expire themselves.
+func UpdateCookieTTLRule(c echo.Context) error {
+	var rule models.CookieTTLRule
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	rule.Domain = c.Param("domain")
+	if rule.TTLSeconds < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "ttlSeconds cannot be negative"})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	_, err := dbClient.Database("scrapeit").Collection("cookie_ttl_rules").ReplaceOne(c.Request().Context(), bson.M{"_id": rule.Domain}, rule, options.Replace().SetUpsert(true))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+func DeleteCookieTTLRule(c echo.Context) error {
+	dbClient, _ := models.GetDbClient()
+	if _, err := dbClient.Database("scrapeit").Collection("cookie_ttl_rules").DeleteOne(c.Request().Context(), bson.M{"_id": c.Param("domain")}); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Cookie TTL rule deleted successfully"})
+}
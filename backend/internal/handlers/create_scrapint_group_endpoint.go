@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"scrapeit/internal/cron"
 	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
 
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson"
@@ -24,6 +25,9 @@ func CreateScrapingGroupEndpoint(c echo.Context) error {
 	if err := c.Bind(&body); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
+	if err := scraper.CheckArchiveHTML(body.NewEndpoint); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
 
 	var relevantGroup *models.ScrapeGroup
 
@@ -47,6 +51,7 @@ func CreateScrapingGroupEndpoint(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	body.NewEndpoint.KeepSecrets(nil)
 	relevantGroup.Endpoints = append(relevantGroup.Endpoints, body.NewEndpoint)
 
 	updateQuery := bson.M{"$set": bson.M{"endpoints": relevantGroup.Endpoints}}
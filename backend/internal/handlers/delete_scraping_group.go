@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"scrapeit/internal/cron"
 	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
 
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson"
@@ -76,6 +77,22 @@ func DeleteScrapingGroup(c echo.Context) error {
 		})
 	}
 
+	_, err = dbClient.Database("scrapeit").Collection("element_snapshots").DeleteMany(c.Request().Context(), endpointsFilter)
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	err = scraper.DeletePageFailures(c.Request().Context(), group.ID.Hex())
+
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
 	archivedFilter := bson.M{"originalId": groupIdObj}
 	cursor, err := dbClient.Database("scrapeit").Collection("scrape_groups").Find(c.Request().Context(), archivedFilter)
 
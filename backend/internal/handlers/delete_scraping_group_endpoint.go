@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"scrapeit/internal/cron"
 	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
 
 	"github.com/labstack/echo/v4"
 
@@ -40,8 +41,18 @@ func deleteScrapingGroupEndpoint(dbClient *mongo.Client, groupId, endpointId str
 	// remove all scrape results for this endpoint
 	scrapeResultsCollection := dbClient.Database("scrapeit").Collection("scrape_results")
 	_, err = scrapeResultsCollection.DeleteMany(context.TODO(), bson.M{"endpointId": endpointId, "groupId": groupIdObj})
+	if err != nil {
+		return err
+	}
+
+	// and the html archived for them
+	_, err = dbClient.Database("scrapeit").Collection("element_snapshots").DeleteMany(context.TODO(), bson.M{"endpointId": endpointId, "groupId": groupIdObj})
+	if err != nil {
+		return err
+	}
 
-	return err
+	// and the pages that failed in its runs
+	return scraper.DeletePageFailures(context.TODO(), groupId, endpointId)
 }
 
 func DeleteScrapingGroupEndpoint(c echo.Context) error {
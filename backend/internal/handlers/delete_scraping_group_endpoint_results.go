@@ -26,8 +26,14 @@ func deleteScrapingGroupEndpointResults(dbClient *mongo.Client, groupId, endpoin
 	}
 	result, err := scrapeResultsCollection.DeleteMany(context.TODO(), bson.M{"endpointId": endpointId, "groupId": groupIdObj})
 
+	if err != nil {
+		return err
+	}
 	fmt.Printf("Delete count: %v\n", result.DeletedCount)
 
+	// the archived html cannot be replayed without its results
+	_, err = dbClient.Database("scrapeit").Collection("element_snapshots").DeleteMany(context.TODO(), bson.M{"endpointId": endpointId, "groupId": groupIdObj})
+
 	return err
 }
 
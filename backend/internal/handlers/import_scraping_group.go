@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
@@ -21,10 +22,18 @@ func ImportScrapingGroup(e echo.Context) error {
 		return e.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 	}
 
+	for _, endpoint := range body.Group.Endpoints {
+		if err := scraper.CheckArchiveHTML(endpoint); err != nil {
+			return e.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("endpoint %s: %v", endpoint.Name, err)})
+		}
+	}
+
 	body.Group.ID = primitive.NewObjectID()
 
+	// Exported groups carry masked passwords, they have to be entered again.
 	for idx := range body.Group.Endpoints {
 		body.Group.Endpoints[idx].ID = uuid.New().String()
+		body.Group.Endpoints[idx].KeepSecrets(nil)
 	}
 
 	dbClient, _ := models.GetDbClient()
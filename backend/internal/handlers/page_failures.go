@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPageFailures lists captured page failures, newest first, filtered by
+// groupId, endpointId and runId.
+func GetPageFailures(c echo.Context) error {
+	filter := bson.M{}
+	for _, param := range []string{"groupId", "endpointId", "runId"} {
+		if value := c.QueryParam(param); value != "" {
+			filter[param] = value
+		}
+	}
+
+	limit := int64(50)
+	if value := c.QueryParam("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+		}
+		limit = parsed
+	}
+
+	dbClient, _ := models.GetDbClient()
+	opts := options.Find().SetSort(bson.M{"created": -1}).SetLimit(limit)
+	result, err := dbClient.Database("scrapeit").Collection("page_failures").Find(c.Request().Context(), filter, opts)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer result.Close(c.Request().Context())
+
+	failures := []models.PageFailure{}
+	if err := result.All(c.Request().Context(), &failures); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, failures)
+}
+
+func GetPageFailure(c echo.Context) error {
+	failure, err := findPageFailure(c)
+	if failure == nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, failure)
+}
+
+// GetPageFailureScreenshot serves the screenshot taken when the page failed.
+func GetPageFailureScreenshot(c echo.Context) error {
+	failure, err := findPageFailure(c)
+	if failure == nil {
+		return err
+	}
+	if failure.ScreenshotID == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No screenshot was captured"})
+	}
+
+	img, err := scraper.PageFailureSnapshot(c.Request().Context(), *failure.ScreenshotID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.Blob(http.StatusOK, "image/png", img)
+}
+
+// GetPageFailureHTML serves the HTML of the failed page as plain text, so
+// the scraped page's scripts never run on this origin.
+func GetPageFailureHTML(c echo.Context) error {
+	failure, err := findPageFailure(c)
+	if failure == nil {
+		return err
+	}
+	if failure.HTMLID == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "No html was captured"})
+	}
+
+	html, err := scraper.PageFailureSnapshot(c.Request().Context(), *failure.HTMLID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, html)
+}
+
+func DeletePageFailure(c echo.Context) error {
+	failure, err := findPageFailure(c)
+	if failure == nil {
+		return err
+	}
+	if err := scraper.DeletePageFailure(c.Request().Context(), *failure); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Page failure deleted successfully"})
+}
+
+// findPageFailure loads the failure named by the id path parameter. When it
+// returns no failure the response has already been written.
+func findPageFailure(c echo.Context) (*models.PageFailure, error) {
+	failureId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid page failure ID"})
+	}
+
+	var failure models.PageFailure
+	dbClient, _ := models.GetDbClient()
+	err = dbClient.Database("scrapeit").Collection("page_failures").FindOne(c.Request().Context(), bson.M{"_id": failureId}).Decode(&failure)
+	if err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{"error": "Page failure not found"})
+	}
+	return &failure, nil
+}
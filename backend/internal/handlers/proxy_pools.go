@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func GetProxyPools(c echo.Context) error {
+	dbClient, _ := models.GetDbClient()
+
+	pools := []models.ProxyPool{}
+	result, err := dbClient.Database("scrapeit").Collection("proxy_pools").Find(c.Request().Context(), bson.M{})
+	if err != nil {
+		fmt.Println("error in finding proxy pools")
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	defer result.Close(c.Request().Context())
+	if err := result.All(c.Request().Context(), &pools); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	for i := range pools {
+		pools[i].RedactSecrets()
+	}
+	return c.JSON(http.StatusOK, pools)
+}
+
+func CreateProxyPool(c echo.Context) error {
+	var pool models.ProxyPool
+	if err := c.Bind(&pool); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := validateProxyPool(pool); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	pool.KeepSecrets(nil)
+	now := primitive.NewDateTimeFromTime(time.Now())
+	pool.ID = primitive.NewObjectID()
+	pool.Created = now
+	pool.Updated = now
+
+	dbClient, _ := models.GetDbClient()
+	if _, err := dbClient.Database("scrapeit").Collection("proxy_pools").InsertOne(c.Request().Context(), pool); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	pool.RedactSecrets()
+	return c.JSON(http.StatusOK, pool)
+}
+
+func UpdateProxyPool(c echo.Context) error {
+	poolId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid proxy pool ID"})
+	}
+
+	var pool models.ProxyPool
+	if err := c.Bind(&pool); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := validateProxyPool(pool); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	collection := dbClient.Database("scrapeit").Collection("proxy_pools")
+	var stored models.ProxyPool
+	if err := collection.FindOne(c.Request().Context(), bson.M{"_id": poolId}).Decode(&stored); err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Proxy pool not found"})
+	}
+	// Proxies keep their password when it is sent back masked.
+	pool.KeepSecrets(&stored)
+
+	result, err := collection.UpdateOne(c.Request().Context(), bson.M{"_id": poolId}, bson.M{"$set": bson.M{
+		"name":    pool.Name,
+		"proxies": pool.Proxies,
+		"updated": primitive.NewDateTimeFromTime(time.Now()),
+	}})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if result.MatchedCount == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Proxy pool not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Proxy pool updated successfully"})
+}
+
+func DeleteProxyPool(c echo.Context) error {
+	poolId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid proxy pool ID"})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	if _, err := dbClient.Database("scrapeit").Collection("proxy_pools").DeleteOne(c.Request().Context(), bson.M{"_id": poolId}); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Proxy pool deleted successfully"})
+}
+
+// CheckProxyPool health checks every proxy of the pool.
+func CheckProxyPool(c echo.Context) error {
+	poolId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid proxy pool ID"})
+	}
+
+	var pool models.ProxyPool
+	dbClient, _ := models.GetDbClient()
+	err = dbClient.Database("scrapeit").Collection("proxy_pools").FindOne(c.Request().Context(), bson.M{"_id": poolId}).Decode(&pool)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Proxy pool not found"})
+	}
+
+	health := make([]scraper.ProxyHealth, len(pool.Proxies))
+	for i, proxy := range pool.Proxies {
+		health[i] = scraper.CheckProxy(proxy)
+	}
+
+	return c.JSON(http.StatusOK, health)
+}
+
+func validateProxyPool(pool models.ProxyPool) error {
+	if pool.Name == "" {
+		return fmt.Errorf("proxy pool name is required")
+	}
+	for _, proxy := range pool.Proxies {
+		switch proxy.Protocol {
+		case models.ProxyProtocolHTTP, models.ProxyProtocolHTTPS, models.ProxyProtocolSOCKS5:
+		default:
+			return fmt.Errorf("unsupported proxy protocol %q", proxy.Protocol)
+		}
+		if proxy.Host == "" || proxy.Port <= 0 {
+			return fmt.Errorf("proxy %s needs a host and a port", proxy.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ReplayScrapeRequest struct {
+	GroupId    string `json:"groupId"`
+	EndpointId string `json:"endpointId"`
+}
+
+// ReplayScrapeHandler extracts an endpoint's results again from the HTML its
+// runs archived, using the endpoint's current field selectors, and updates
+// the stored results. It does not load any page.
+func ReplayScrapeHandler(c echo.Context) error {
+	var body ReplayScrapeRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	// getScrapeGroup has already written the response when it returns no
+	// group.
+	group, err := getScrapeGroup(c, dbClient, body.GroupId)
+	if group == nil {
+		return err
+	}
+
+	endpoint := group.GetEndpointById(body.EndpointId)
+	if endpoint == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Endpoint not found"})
+	}
+	if endpoint.Status == models.ScrapeStatusRunning {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Endpoint is being scraped"})
+	}
+
+	stats, err := scraper.ReplayEndpoint(c.Request().Context(), *endpoint, *group, dbClient)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
@@ -24,6 +24,7 @@ type ScraperEndpointHandlerRequest struct {
 type ScraperEndpointHandlerResponse struct {
 	NewResults      []models.ScrapeResult `json:"newResults"`
 	ReplacedResults []models.ScrapeResult `json:"replacedResults"`
+	Stats           models.ScrapeRunStats `json:"stats"`
 }
 
 func ScrapeEndpointHandler(c echo.Context) error {
@@ -59,11 +60,20 @@ func ScrapeEndpointHandler(c echo.Context) error {
 
 	browser := scraper.GetBrowser()
 
-	results, toReplace, err := scraper.ScrapeEndpoint(*endpointToScrape, *relevantGroup, dbClient, browser)
+	// The run is not bound to the request, it ends when it finishes or is
+	// cancelled through the cancel API.
+	results, toReplace, stats, err := scraper.ScrapeEndpoint(context.Background(), *endpointToScrape, *relevantGroup, dbClient, browser)
 	if err != nil {
+		if err := updateEndpointStatuses(dbClient, relevantGroup.ID, []*models.Endpoint{endpointToScrape}, models.ScrapeStatusIdle); err != nil {
+			fmt.Println("Error updating group:", err)
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	// The client may be gone after a long run, the results are saved
+	// anyway.
+	saveCtx := context.WithoutCancel(c.Request().Context())
+
 	seenUniqueHashes := make(map[string]bool)
 	allResultsCollection := dbClient.Database("scrapeit").Collection("scrape_results")
 
@@ -77,9 +87,11 @@ func ScrapeEndpointHandler(c echo.Context) error {
 		toInsert = append(toInsert, r)
 	}
 
-	_, err = allResultsCollection.InsertMany(context.TODO(), toInsert, &options.InsertManyOptions{})
-	if err != nil {
-		fmt.Println("Error inserting new results:", err)
+	if len(toInsert) > 0 {
+		_, err = allResultsCollection.InsertMany(saveCtx, toInsert, &options.InsertManyOptions{})
+		if err != nil {
+			fmt.Println("Error inserting new results:", err)
+		}
 	}
 
 	fmt.Println("Here go the to replace", toReplace)
@@ -98,7 +110,7 @@ func ScrapeEndpointHandler(c echo.Context) error {
 
 		fmt.Println("Len writes", len(bulkWrites))
 
-		_, err = allResultsCollection.BulkWrite(c.Request().Context(), bulkWrites)
+		_, err = allResultsCollection.BulkWrite(saveCtx, bulkWrites)
 		if err != nil {
 			fmt.Println("Error updating existing results:", err)
 		}
@@ -106,14 +118,18 @@ func ScrapeEndpointHandler(c echo.Context) error {
 
 	// update group and set endpoint status to idle
 	endpointToScrape.Status = models.ScrapeStatusIdle
-	_, err = groupCollection.UpdateOne(c.Request().Context(), groupQuery, bson.M{"$set": bson.M{"endpoints": relevantGroup.Endpoints}})
+	_, err = groupCollection.UpdateOne(saveCtx, groupQuery, bson.M{"$set": bson.M{"endpoints": relevantGroup.Endpoints}})
 	if err != nil {
 		fmt.Println("Error updating group:", err)
 	}
 
+	if err := saveEndpointRunStats(saveCtx, dbClient, relevantGroup.ID, endpointToScrape.ID, stats); err != nil {
+		fmt.Println("Error saving run stats:", err)
+	}
+
 	notificationConfigs := []models.NotificationConfig{}
 
-	notificationConfigResult, err := dbClient.Database("scrapeit").Collection("notification_configs").Find(c.Request().Context(), bson.M{"groupId": relevantGroup.ID})
+	notificationConfigResult, err := dbClient.Database("scrapeit").Collection("notification_configs").Find(saveCtx, bson.M{"groupId": relevantGroup.ID})
 	if err != nil {
 		fmt.Println("Failed to get notification configs")
 	} else {
@@ -136,5 +152,6 @@ func ScrapeEndpointHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, ScraperEndpointHandlerResponse{
 		NewResults:      results,
 		ReplacedResults: toReplace,
+		Stats:           stats,
 	})
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"scrapeit/internal/models"
 	"scrapeit/internal/scraper"
@@ -14,11 +16,12 @@ type ScrapeEndpointTestHandlerRequest struct {
 }
 
 type TestScrapeGroup struct {
-	ID            string            `json:"id"`
-	Name          string            `json:"name"`
-	Fields        []models.Field    `json:"fields"`
-	Endpoints     []models.Endpoint `json:"endpoints"`
-	WithThumbnail bool              `json:"withThumbnail"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	Fields           []models.Field    `json:"fields"`
+	Endpoints        []models.Endpoint `json:"endpoints"`
+	WithThumbnail    bool              `json:"withThumbnail"`
+	RespectRobotsTxt bool              `json:"respectRobotsTxt"`
 }
 
 func ScrapeEndpointTestHandler(c echo.Context) error {
@@ -29,17 +32,49 @@ func ScrapeEndpointTestHandler(c echo.Context) error {
 
 	dbClient, _ := models.GetDbClient()
 
+	// A saved endpoint comes back with its password masked.
+	if login := body.Group.Endpoints[0].Login; login != nil && login.Password == models.SecretMask {
+		var stored *models.Endpoint
+		if storedGroup, err := getGroupById(c.Request().Context(), body.Group.ID, dbClient); err == nil {
+			stored = storedGroup.GetEndpointById(body.Group.Endpoints[0].ID)
+		}
+		body.Group.Endpoints[0].KeepSecrets(stored)
+	}
+
 	group := models.ScrapeGroup{
-		ID:            primitive.NewObjectID(),
-		Name:          body.Group.Name,
-		Fields:        body.Group.Fields,
-		Endpoints:     body.Group.Endpoints,
-		WithThumbnail: body.Group.WithThumbnail,
+		ID:               primitive.NewObjectID(),
+		Name:             body.Group.Name,
+		Fields:           body.Group.Fields,
+		Endpoints:        body.Group.Endpoints,
+		WithThumbnail:    body.Group.WithThumbnail,
+		RespectRobotsTxt: body.Group.RespectRobotsTxt,
+	}
+
+	// Disallowed URLs are reported even when the group does not respect
+	// robots.txt yet, so they show up before the endpoint is activated.
+	for _, warning := range scraper.RobotsTxtWarnings(c.Request().Context(), body.Group.Endpoints[0]) {
+		fmt.Println("Warning:", warning)
+		c.Response().Header().Add("X-Scrape-Warning", warning)
 	}
 
 	browser := scraper.GetBrowser()
 
 	results, _, err := scraper.ScrapeEndpointTest(body.Group.Endpoints[0], group, dbClient, browser)
+	var actionErr *scraper.PageActionError
+	if errors.As(err, &actionErr) {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+			"error":       err.Error(),
+			"actionIndex": actionErr.Index,
+			"actionId":    actionErr.Action.ID,
+		})
+	}
+	var pageErr *scraper.PageError
+	if errors.As(err, &pageErr) {
+		return c.JSON(http.StatusBadGateway, map[string]interface{}{
+			"error":     err.Error(),
+			"errorKind": pageErr.Kind,
+		})
+	}
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -22,8 +22,9 @@ type ScraperEndpointsHandlerRequest struct {
 }
 
 type ScraperEndpointsHandlerResponse struct {
-	NewResults      []models.ScrapeResult `json:"newResults"`
-	ReplacedResults []models.ScrapeResult `json:"replacedResults"`
+	NewResults      []models.ScrapeResult            `json:"newResults"`
+	ReplacedResults []models.ScrapeResult            `json:"replacedResults"`
+	Stats           map[string]models.ScrapeRunStats `json:"stats"`
 }
 
 func ScrapeEndpointsHandler(c echo.Context) error {
@@ -54,7 +55,7 @@ func ScrapeEndpointsHandler(c echo.Context) error {
 	}
 	defer updateEndpointStatuses(dbClient, group.ID, endpointsToScrape, models.ScrapeStatusIdle)
 
-	results, toReplaceResults := scrapeEndpoints(c, dbClient, group, endpointsToScrape)
+	results, toReplaceResults, stats := scrapeEndpoints(c, dbClient, group, endpointsToScrape)
 
 	if err := insertNewResults(dbClient, results); err != nil {
 		fmt.Printf("Failed to insert new results %v\n", err)
@@ -73,6 +74,7 @@ func ScrapeEndpointsHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, ScraperEndpointsHandlerResponse{
 		NewResults:      results,
 		ReplacedResults: toReplaceResults,
+		Stats:           stats,
 	})
 }
 
@@ -175,31 +177,53 @@ func updateGroupEndpoints(dbClient *mongo.Client, group *models.ScrapeGroup) err
 	return err
 }
 
-func scrapeEndpoints(c echo.Context, dbClient *mongo.Client, group *models.ScrapeGroup, endpoints []*models.Endpoint) ([]models.ScrapeResult, []models.ScrapeResult) {
-	resultsChan := make(chan []models.ScrapeResult)
-	toReplaceChan := make(chan []models.ScrapeResult)
+func scrapeEndpoints(c echo.Context, dbClient *mongo.Client, group *models.ScrapeGroup, endpoints []*models.Endpoint) ([]models.ScrapeResult, []models.ScrapeResult, map[string]models.ScrapeRunStats) {
+	type endpointRun struct {
+		endpointId string
+		results    []models.ScrapeResult
+		toReplace  []models.ScrapeResult
+		stats      models.ScrapeRunStats
+	}
+	runsChan := make(chan endpointRun)
 	browser := scraper.GetBrowser()
 
 	for _, endpoint := range endpoints {
 		go func(endpoint models.Endpoint) {
-			results, toReplace, err := scraper.ScrapeEndpoint(endpoint, *group, dbClient, browser)
+			results, toReplace, stats, err := scraper.ScrapeEndpoint(context.Background(), endpoint, *group, dbClient, browser)
 			if err != nil {
-				resultsChan <- nil
-				toReplaceChan <- nil
-			} else {
-				resultsChan <- results
-				toReplaceChan <- toReplace
+				fmt.Printf("Failed to scrape endpoint %s: %v\n", endpoint.ID, err)
+				results, toReplace = nil, nil
+			}
+			if err := saveEndpointRunStats(context.TODO(), dbClient, group.ID, endpoint.ID, stats); err != nil {
+				fmt.Printf("Failed to save run stats for endpoint %s: %v\n", endpoint.ID, err)
 			}
+			runsChan <- endpointRun{endpointId: endpoint.ID, results: results, toReplace: toReplace, stats: stats}
 		}(*endpoint)
 	}
 
 	var results []models.ScrapeResult
 	var toReplaceResults []models.ScrapeResult
+	stats := make(map[string]models.ScrapeRunStats, len(endpoints))
 	for range endpoints {
-		results = append(results, <-resultsChan...)
-		toReplaceResults = append(toReplaceResults, <-toReplaceChan...)
+		run := <-runsChan
+		results = append(results, run.results...)
+		toReplaceResults = append(toReplaceResults, run.toReplace...)
+		stats[run.endpointId] = run.stats
+	}
+	return results, toReplaceResults, stats
+}
+
+// saveEndpointRunStats stores the stats of the latest run on the endpoint.
+func saveEndpointRunStats(ctx context.Context, dbClient *mongo.Client, groupID primitive.ObjectID, endpointId string, stats models.ScrapeRunStats) error {
+	groupCollection := dbClient.Database("scrapeit").Collection("scrape_groups")
+	_, err := groupCollection.UpdateOne(ctx,
+		bson.M{"_id": groupID, "endpoints.id": endpointId},
+		bson.M{"$set": bson.M{"endpoints.$.lastRunStats": stats}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save run stats: %w", err)
 	}
-	return results, toReplaceResults
+	return nil
 }
 
 func insertNewResults(dbClient *mongo.Client, results []models.ScrapeResult) error {
@@ -54,5 +54,6 @@ func GetScrapingGroup(c echo.Context) error {
 		})
 	}
 
+	group.RedactSecrets()
 	return c.JSON(http.StatusOK, group)
 }
@@ -30,6 +30,9 @@ func GetScrapingGroups(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	for i := range allGroups {
+		allGroups[i].RedactSecrets()
+	}
 	return c.JSON(http.StatusOK, allGroups)
 }
 
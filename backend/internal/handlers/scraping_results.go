@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"scrapeit/internal/models"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"go.mongodb.org/mongo-driver/bson"
@@ -25,6 +28,9 @@ const (
 	FilterOperatorNotEqual    FilterOperator = "!="
 	FilterOperatorGreaterThan FilterOperator = ">"
 	FilterOperatorLessThan    FilterOperator = "<"
+	// FilterOperatorContains matches text values containing the filter value
+	// and list values with an item containing it.
+	FilterOperatorContains FilterOperator = "contains"
 )
 
 type SearchFilter struct {
@@ -82,10 +88,17 @@ func getScrapeResults(
 	}
 
 	if len(params.Filters) > 0 {
+		group, err := getGroupById(ctx, params.GroupId, client)
+		if err != nil {
+			return nil, false, err
+		}
+
 		// Initialize the filter structure for fields
 		var fieldConditions []bson.M
 
 		for _, requestFilter := range params.Filters {
+			requestFilter.Value = filterValueForField(group.GetFieldById(requestFilter.FieldId), requestFilter.Value)
+
 			var condition bson.M
 			switch requestFilter.Operator {
 			case FilterOperatorEqual:
@@ -124,6 +137,19 @@ func getScrapeResults(
 						},
 					},
 				}
+			case FilterOperatorContains:
+				var valueCondition interface{} = requestFilter.Value
+				if text, ok := requestFilter.Value.(string); ok {
+					valueCondition = bson.M{"$regex": regexp.QuoteMeta(text), "$options": "i"}
+				}
+				condition = bson.M{
+					"fields": bson.M{
+						"$elemMatch": bson.M{
+							"fieldId": requestFilter.FieldId,
+							"value":   valueCondition,
+						},
+					},
+				}
 			default:
 				fmt.Println("Unknown filter operator:", requestFilter.Operator)
 				continue
@@ -142,16 +168,16 @@ func getScrapeResults(
 	// Default sort by timestampLastUpdate
 	if params.Sort.FieldId == "" {
 		pipeline = mongo.Pipeline{
-			{{"$match", filter}},
-			{{"$sort", bson.D{{"timestampLastUpdate", -1}}}},
-			{{"$skip", params.Offset}},
-			{{"$limit", params.Limit}},
+			{{Key: "$match", Value: filter}},
+			{{Key: "$sort", Value: bson.D{{Key: "timestampLastUpdate", Value: -1}}}},
+			{{Key: "$skip", Value: params.Offset}},
+			{{Key: "$limit", Value: params.Limit}},
 		}
 	} else {
 		// Sort by specific fieldId's value
 		pipeline = mongo.Pipeline{
-			{{"$match", filter}},
-			{{"$addFields", bson.M{
+			{{Key: "$match", Value: filter}},
+			{{Key: "$addFields", Value: bson.M{
 				"sortField": bson.M{
 					"$arrayElemAt": bson.A{
 						bson.M{"$filter": bson.M{
@@ -162,10 +188,10 @@ func getScrapeResults(
 					},
 				},
 			}}},
-			{{"$sort", bson.D{{"sortField.value", params.Sort.Order}}}},
-			{{"$skip", params.Offset}},
-			{{"$limit", params.Limit}},
-			{{"$unset", "sortField"}},
+			{{Key: "$sort", Value: bson.D{{Key: "sortField.value", Value: params.Sort.Order}}}},
+			{{Key: "$skip", Value: params.Offset}},
+			{{Key: "$limit", Value: params.Limit}},
+			{{Key: "$unset", Value: "sortField"}},
 		}
 	}
 	cursor, err := client.Database("scrapeit").Collection(collectionName).Aggregate(ctx, pipeline)
@@ -188,25 +214,25 @@ func getScrapeResults(
 
 	if params.Sort.FieldId == "" {
 		pipeline = mongo.Pipeline{
-			{{"$match", filter}},
-			{{"$sort", bson.D{{"timestampLastUpdate", -1}}}},
-			{{"$skip", params.Offset + limit}},
-			{{"$limit", 1}},
+			{{Key: "$match", Value: filter}},
+			{{Key: "$sort", Value: bson.D{{Key: "timestampLastUpdate", Value: -1}}}},
+			{{Key: "$skip", Value: params.Offset + limit}},
+			{{Key: "$limit", Value: 1}},
 		}
 	} else {
 		pipeline = mongo.Pipeline{
-			{{"$match", filter}},
-			{{"$unwind", "$fields"}},
-			{{"$match", bson.M{"fields.fieldId": params.Sort.FieldId}}},
-			{{"$sort", bson.D{{"fields.value", params.Sort.Order}}}},
-			{{"$group", bson.M{
+			{{Key: "$match", Value: filter}},
+			{{Key: "$unwind", Value: "$fields"}},
+			{{Key: "$match", Value: bson.M{"fields.fieldId": params.Sort.FieldId}}},
+			{{Key: "$sort", Value: bson.D{{Key: "fields.value", Value: params.Sort.Order}}}},
+			{{Key: "$group", Value: bson.M{
 				"_id": "$_id",
 				"doc": bson.M{"$first": "$$ROOT"},
 			}}},
-			{{"$replaceRoot", bson.M{"newRoot": "$doc"}}},
-			{{"$sort", bson.D{{"timestampLastUpdate", -1}}}}, // Apply default sort after grouping
-			{{"$skip", params.Offset + limit}},
-			{{"$limit", 1}},
+			{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$doc"}}},
+			{{Key: "$sort", Value: bson.D{{Key: "timestampLastUpdate", Value: -1}}}}, // Apply default sort after grouping
+			{{Key: "$skip", Value: params.Offset + limit}},
+			{{Key: "$limit", Value: 1}},
 		}
 	}
 
@@ -224,6 +250,35 @@ func getScrapeResults(
 	return endpointResults, hasMore, nil
 }
 
+// filterValueForField converts a filter value sent as JSON to the type stored
+// for the field, so date and boolean fields can be compared.
+func filterValueForField(field *models.Field, value interface{}) interface{} {
+	if field == nil {
+		return value
+	}
+
+	switch field.Type {
+	case models.FieldTypeDate:
+		switch v := value.(type) {
+		case string:
+			for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+				if date, err := time.Parse(layout, v); err == nil {
+					return date
+				}
+			}
+		case float64:
+			return time.Unix(int64(v), 0)
+		}
+	case models.FieldTypeBoolean:
+		if v, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+	}
+	return value
+}
+
 func GetScrapingResults(c echo.Context) error {
 	dbClient, _ := models.GetDbClient()
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"scrapeit/internal/cron"
 	"scrapeit/internal/models"
+	"scrapeit/internal/scraper"
 	"strings"
 
 	"github.com/labstack/echo/v4"
@@ -48,6 +49,9 @@ func UpdateScrapingGroupEndpoint(c echo.Context) error {
 	}
 
 	newEndpoint := req.Endpoint
+	if err := scraper.CheckArchiveHTML(newEndpoint); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
 
 	go cronManager.DestroyJob(groupIdString, endpointId)
 
@@ -55,6 +59,7 @@ func UpdateScrapingGroupEndpoint(c echo.Context) error {
 	if oldEndpoint == nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid Endpoint Id")
 	}
+	newEndpoint.KeepSecrets(oldEndpoint)
 
 	for _, oldSelector := range oldEndpoint.DetailFieldSelectors {
 		var foundNewSelector *models.FieldSelector
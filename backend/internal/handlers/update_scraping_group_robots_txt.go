@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"scrapeit/internal/models"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type UpdateScrapingGroupRobotsTxtRequest struct {
+	RespectRobotsTxt bool `json:"respectRobotsTxt"`
+}
+
+func UpdateScrapingGroupRobotsTxt(c echo.Context) error {
+	var body UpdateScrapingGroupRobotsTxtRequest
+	if err := c.Bind(&body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	groupId, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid group ID"})
+	}
+
+	dbClient, _ := models.GetDbClient()
+	result, err := dbClient.Database("scrapeit").Collection("scrape_groups").UpdateOne(c.Request().Context(), bson.M{"_id": groupId}, bson.M{"$set": bson.M{
+		"respectRobotsTxt": body.RespectRobotsTxt,
+		"updated":          primitive.NewDateTimeFromTime(time.Now()),
+	}})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if result.MatchedCount == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "robots.txt setting updated successfully"})
+}
@@ -71,6 +71,7 @@ func UpdateScrapingGroupSchema(c echo.Context) error {
 		groupCopy.Fields = group.Fields
 		groupCopy.Endpoints = group.Endpoints
 		groupCopy.WithThumbnail = group.WithThumbnail
+		groupCopy.RespectRobotsTxt = group.RespectRobotsTxt
 		groupCopy.VersionTag = req.VersionTag
 		groupCopy.ID = newGroupId
 		groupCopy.Created = group.Created
@@ -43,7 +43,7 @@ func FindScrapeResultExists(ctx context.Context, client *mongo.Client, endpointI
 	for _, field := range resultFields {
 		switch field.FieldID {
 		case uniqueIdFieldId:
-			uniqueIdValue = field.Value.(string)
+			uniqueIdValue = FormatFieldValue(field.Value)
 		}
 	}
 
@@ -110,7 +110,7 @@ func FindScrapeResultExists(ctx context.Context, client *mongo.Client, endpointI
 
 			if potentialField.FieldID != uniqueIdFieldId &&
 				field.FieldID == potentialField.FieldID &&
-				field.Value != potentialField.Value && !isLinkTypeValue {
+				!FieldValuesEqual(field.Value, potentialField.Value) && !isLinkTypeValue {
 				fieldName := ""
 				for _, schemaField := range schema {
 					if schemaField.ID == field.FieldID {
@@ -118,7 +118,7 @@ func FindScrapeResultExists(ctx context.Context, client *mongo.Client, endpointI
 					}
 				}
 
-				fmt.Printf("Field %s is different: %s != %s\n", fieldName, field.Value, potentialField.Value)
+				fmt.Printf("Field %s is different: %v != %v\n", fieldName, field.Value, potentialField.Value)
 
 				areSame = false
 				break
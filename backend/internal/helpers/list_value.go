@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListValueSeparator joins list items when a list has to be flattened into
+// a single string.
+const ListValueSeparator = "; "
+
+// ListValueToStrings returns the items of a list field value. Freshly
+// scraped lists are []string, lists read back from the database are
+// primitive.A.
+func ListValueToStrings(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case primitive.A:
+		return interfacesToStrings(v), true
+	case []interface{}:
+		return interfacesToStrings(v), true
+	default:
+		return nil, false
+	}
+}
+
+func interfacesToStrings(values []interface{}) []string {
+	items := make([]string, len(values))
+	for i, value := range values {
+		items[i] = fmt.Sprintf("%v", value)
+	}
+	return items
+}
+
+// FormatFieldValue flattens a field value into a single string.
+func FormatFieldValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if items, ok := ListValueToStrings(value); ok {
+		return strings.Join(items, ListValueSeparator)
+	}
+	if date, ok := DateValueToTime(value); ok {
+		return date.Format(time.RFC3339)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// FieldValuesEqual compares two field values. Lists are equal when they have
+// the same items in the same order.
+func FieldValuesEqual(a, b interface{}) bool {
+	aItems, aIsList := ListValueToStrings(a)
+	bItems, bIsList := ListValueToStrings(b)
+	if aIsList || bIsList {
+		if aIsList != bIsList || len(aItems) != len(bItems) {
+			return false
+		}
+		for i := range aItems {
+			if aItems[i] != bItems[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	aDate, aIsDate := DateValueToTime(a)
+	bDate, bIsDate := DateValueToTime(b)
+	if aIsDate || bIsDate {
+		// BSON dates only keep milliseconds.
+		return aIsDate && bIsDate && aDate.UnixMilli() == bDate.UnixMilli()
+	}
+
+	return a == b
+}
+
+// DateValueToTime returns the time of a date field value. Freshly scraped
+// dates are time.Time, dates read back from the database are
+// primitive.DateTime.
+func DateValueToTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case primitive.DateTime:
+		return v.Time(), true
+	default:
+		return time.Time{}, false
+	}
+}
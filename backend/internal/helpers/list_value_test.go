@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFieldValuesEqual(t *testing.T) {
+	date := time.Date(2024, 3, 15, 14, 35, 20, 123456789, time.UTC)
+
+	tests := []struct {
+		name string
+		a    interface{}
+		b    interface{}
+		want bool
+	}{
+		{"same text", "a", "a", true},
+		{"different text", "a", "b", false},
+		{"same number", 1.5, 1.5, true},
+		{"different number", 1.5, 2.0, false},
+		{"same bool", true, true, true},
+		{"nil values", nil, nil, true},
+		{"nil and text", nil, "", false},
+		{"same list", []string{"a", "b"}, []string{"a", "b"}, true},
+		{"stored list", []string{"a", "b"}, primitive.A{"a", "b"}, true},
+		{"list order", []string{"a", "b"}, primitive.A{"b", "a"}, false},
+		{"list length", []string{"a"}, primitive.A{"a", "b"}, false},
+		{"empty lists", []string{}, primitive.A{}, true},
+		{"list and text", []string{"a"}, "a", false},
+		{"stored date", date, primitive.NewDateTimeFromTime(date), true},
+		{"different date", date, primitive.NewDateTimeFromTime(date.Add(time.Second)), false},
+		{"date and text", date, date.Format(time.RFC3339), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldValuesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("FieldValuesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := FieldValuesEqual(tt.b, tt.a); got != tt.want {
+				t.Errorf("FieldValuesEqual(%v, %v) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, ""},
+		{"text", "abc", "abc"},
+		{"number", 12.5, "12.5"},
+		{"bool", false, "false"},
+		{"list", []string{"a", "b"}, "a; b"},
+		{"stored list", primitive.A{"a", 1}, "a; 1"},
+		{"date", time.Date(2024, 3, 15, 14, 35, 0, 0, time.UTC), "2024-03-15T14:35:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatFieldValue(tt.value); got != tt.want {
+				t.Errorf("FormatFieldValue(%v) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"scrapeit/internal/models"
+	"strings"
+)
+
+var defaultBooleanFalseValues = []string{"false", "no", "0", "off", "n"}
+
+// ParseBooleanString parses scraped text into a bool according to config.
+// Configured values match when the text contains them, ignoring case. False
+// values are checked first so "not available" can win over "available".
+func ParseBooleanString(text string, config *models.BooleanParsingConfig) bool {
+	normalized := strings.ToLower(strings.TrimSpace(text))
+
+	if config == nil || len(config.FalseValues) == 0 {
+		if containsString(defaultBooleanFalseValues, normalized) {
+			return false
+		}
+	} else if containsAnyFold(normalized, config.FalseValues) {
+		return false
+	}
+
+	if config == nil || len(config.TrueValues) == 0 {
+		return normalized != ""
+	}
+	return containsAnyFold(normalized, config.TrueValues)
+}
+
+func containsAnyFold(normalized string, values []string) bool {
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if value != "" && strings.Contains(normalized, value) {
+			return true
+		}
+	}
+	return false
+}
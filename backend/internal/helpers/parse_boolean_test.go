@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"scrapeit/internal/models"
+	"testing"
+)
+
+func TestParseBooleanString(t *testing.T) {
+	stock := &models.BooleanParsingConfig{
+		TrueValues:  []string{"in stock", "available"},
+		FalseValues: []string{"not available", "sold out"},
+	}
+
+	tests := []struct {
+		name   string
+		text   string
+		config *models.BooleanParsingConfig
+		want   bool
+	}{
+		{"default true", "yes", nil, true},
+		{"default any text", "Free shipping", nil, true},
+		{"default empty", "  ", nil, false},
+		{"default false", " No ", nil, false},
+		{"default zero", "0", nil, false},
+		{"configured true", "Only 3 left in stock!", stock, true},
+		{"configured false wins", "Not available", stock, false},
+		{"configured false", "SOLD OUT", stock, false},
+		{"configured unknown", "call us", stock, false},
+		{"only false values", "whatever", &models.BooleanParsingConfig{FalseValues: []string{"none"}}, true},
+		{"only false values match", "None", &models.BooleanParsingConfig{FalseValues: []string{"none"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseBooleanString(tt.text, tt.config); got != tt.want {
+				t.Errorf("ParseBooleanString(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"scrapeit/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type dateUnit int
+
+const (
+	dateUnitSecond dateUnit = iota
+	dateUnitMinute
+	dateUnitHour
+	dateUnitDay
+	dateUnitWeek
+	dateUnitMonth
+	dateUnitYear
+)
+
+type relativeUnit struct {
+	stem string
+	unit dateUnit
+}
+
+// dateLocale holds the words needed to parse dates written in a language.
+type dateLocale struct {
+	months         map[string]time.Month
+	shortMonths    map[string]time.Month
+	units          []relativeUnit
+	shortUnits     map[string]dateUnit
+	agoMarkers     []string
+	oneWords       []string
+	nowWords       []string
+	todayWords     []string
+	yesterdayWords []string
+	dayFirst       bool
+}
+
+var dateLocales = map[string]dateLocale{
+	"en": {
+		months: map[string]time.Month{
+			"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+			"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+			"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+		},
+		shortMonths: map[string]time.Month{
+			"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+			"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September,
+			"sept": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+		},
+		units: []relativeUnit{
+			{"second", dateUnitSecond}, {"sec", dateUnitSecond}, {"minute", dateUnitMinute}, {"min", dateUnitMinute},
+			{"hour", dateUnitHour}, {"hr", dateUnitHour}, {"day", dateUnitDay}, {"week", dateUnitWeek},
+			{"wk", dateUnitWeek}, {"month", dateUnitMonth}, {"year", dateUnitYear}, {"yr", dateUnitYear},
+		},
+		shortUnits: map[string]dateUnit{
+			"s": dateUnitSecond, "m": dateUnitMinute, "h": dateUnitHour, "d": dateUnitDay, "w": dateUnitWeek, "y": dateUnitYear,
+		},
+		agoMarkers:     []string{"ago"},
+		oneWords:       []string{"a", "an", "one"},
+		nowWords:       []string{"now"},
+		todayWords:     []string{"today"},
+		yesterdayWords: []string{"yesterday"},
+	},
+	"de": {
+		months: map[string]time.Month{
+			"januar": time.January, "februar": time.February, "märz": time.March, "april": time.April,
+			"mai": time.May, "juni": time.June, "juli": time.July, "august": time.August,
+			"september": time.September, "oktober": time.October, "november": time.November, "dezember": time.December,
+		},
+		shortMonths: map[string]time.Month{
+			"jan": time.January, "feb": time.February, "mär": time.March, "mrz": time.March, "apr": time.April,
+			"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September,
+			"sept": time.September, "okt": time.October, "nov": time.November, "dez": time.December,
+		},
+		units: []relativeUnit{
+			{"sekunde", dateUnitSecond}, {"sek", dateUnitSecond}, {"minute", dateUnitMinute}, {"min", dateUnitMinute},
+			{"stunde", dateUnitHour}, {"std", dateUnitHour}, {"tag", dateUnitDay}, {"woche", dateUnitWeek},
+			{"monat", dateUnitMonth}, {"jahr", dateUnitYear},
+		},
+		agoMarkers:     []string{"vor"},
+		oneWords:       []string{"ein", "eine", "einem", "einer"},
+		nowWords:       []string{"jetzt", "gerade"},
+		todayWords:     []string{"heute"},
+		yesterdayWords: []string{"gestern"},
+		dayFirst:       true,
+	},
+	"fr": {
+		months: map[string]time.Month{
+			"janvier": time.January, "février": time.February, "mars": time.March, "avril": time.April,
+			"mai": time.May, "juin": time.June, "juillet": time.July, "août": time.August,
+			"septembre": time.September, "octobre": time.October, "novembre": time.November, "décembre": time.December,
+		},
+		shortMonths: map[string]time.Month{
+			"janv": time.January, "févr": time.February, "fév": time.February, "avr": time.April,
+			"juil": time.July, "sept": time.September, "oct": time.October, "nov": time.November, "déc": time.December,
+		},
+		units: []relativeUnit{
+			{"seconde", dateUnitSecond}, {"sec", dateUnitSecond}, {"minute", dateUnitMinute}, {"min", dateUnitMinute},
+			{"heure", dateUnitHour}, {"jour", dateUnitDay}, {"semaine", dateUnitWeek}, {"mois", dateUnitMonth},
+			{"an", dateUnitYear},
+		},
+		agoMarkers:     []string{"il y a"},
+		oneWords:       []string{"un", "une"},
+		nowWords:       []string{"maintenant"},
+		todayWords:     []string{"aujourd"},
+		yesterdayWords: []string{"hier"},
+		dayFirst:       true,
+	},
+	"es": {
+		months: map[string]time.Month{
+			"enero": time.January, "febrero": time.February, "marzo": time.March, "abril": time.April,
+			"mayo": time.May, "junio": time.June, "julio": time.July, "agosto": time.August,
+			"septiembre": time.September, "setiembre": time.September, "octubre": time.October,
+			"noviembre": time.November, "diciembre": time.December,
+		},
+		shortMonths: map[string]time.Month{
+			"ene": time.January, "feb": time.February, "mar": time.March, "abr": time.April,
+			"may": time.May, "jun": time.June, "jul": time.July, "ago": time.August, "sep": time.September,
+			"sept": time.September, "oct": time.October, "nov": time.November, "dic": time.December,
+		},
+		units: []relativeUnit{
+			{"segundo", dateUnitSecond}, {"seg", dateUnitSecond}, {"minuto", dateUnitMinute}, {"min", dateUnitMinute},
+			{"hora", dateUnitHour}, {"día", dateUnitDay}, {"dia", dateUnitDay}, {"semana", dateUnitWeek},
+			{"mes", dateUnitMonth}, {"año", dateUnitYear}, {"ano", dateUnitYear},
+		},
+		agoMarkers:     []string{"hace"},
+		oneWords:       []string{"un", "una"},
+		nowWords:       []string{"ahora"},
+		todayWords:     []string{"hoy"},
+		yesterdayWords: []string{"ayer"},
+		dayFirst:       true,
+	},
+}
+
+var (
+	dateWordRegex      = regexp.MustCompile(`[\p{L}\d]+`)
+	monthTokenRegex    = regexp.MustCompile(`\p{L}+\.?`)
+	amountAndUnitRegex = regexp.MustCompile(`^(\d+)(\p{L}+)$`)
+)
+
+func getDateLocale(name string) dateLocale {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(name, "-_"); i != -1 {
+		name = name[:i]
+	}
+	if locale, ok := dateLocales[name]; ok {
+		return locale
+	}
+	return dateLocales["en"]
+}
+
+func defaultDateLayouts(locale dateLocale) []string {
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		"2.1.2006 15:04",
+		"2.1.2006",
+		"2.1.06",
+	}
+	if locale.dayFirst {
+		layouts = append(layouts, "2/1/2006 15:04", "2/1/2006")
+	} else {
+		layouts = append(layouts, "1/2/2006 15:04", "1/2/2006")
+	}
+	return append(layouts,
+		"Jan 2, 2006",
+		"January 2, 2006",
+		"Jan 2 2006",
+		"January 2 2006",
+		"2 Jan 2006",
+		"2 January 2006",
+		"2. Jan 2006",
+		"2. January 2006",
+		"Jan 2",
+		"January 2",
+		"2 Jan",
+		"2 January",
+		"2. Jan",
+		"2. January",
+		time.RFC1123,
+		time.RFC1123Z,
+	)
+}
+
+// ParseDateString parses scraped text into a date according to config. now
+// anchors relative dates and dates without a year.
+func ParseDateString(text string, config *models.DateParsingConfig, now time.Time) (time.Time, error) {
+	if config == nil {
+		config = &models.DateParsingConfig{}
+	}
+
+	location := time.UTC
+	if strings.TrimSpace(config.Timezone) != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %s: %w", config.Timezone, err)
+		}
+		location = loc
+	}
+	now = now.In(location)
+	locale := getDateLocale(config.Locale)
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\u00A0", " "))
+	if text == "" {
+		return time.Time{}, fmt.Errorf("no date text")
+	}
+
+	if !config.DisableRelativeDates {
+		if date, ok := parseRelativeDate(text, locale, now); ok {
+			return date, nil
+		}
+	}
+
+	layouts := config.Layouts
+	if len(layouts) == 0 {
+		layouts = defaultDateLayouts(locale)
+	}
+	normalized := translateMonthNames(text, locale)
+	for _, layout := range layouts {
+		date, err := time.ParseInLocation(layout, normalized, location)
+		if err != nil {
+			continue
+		}
+		// Dates like "Aug 12" are in the current year unless that would put
+		// them in the future.
+		if date.Year() == 0 {
+			date = date.AddDate(now.Year(), 0, 0)
+			if date.After(now.AddDate(0, 0, 1)) {
+				date = date.AddDate(-1, 0, 0)
+			}
+		}
+		return date, nil
+	}
+
+	return time.Time{}, fmt.Errorf("could not parse date %q", text)
+}
+
+// translateMonthNames replaces localized month names with the English names
+// time.Parse understands. Abbreviations lose their trailing dot.
+func translateMonthNames(text string, locale dateLocale) string {
+	return monthTokenRegex.ReplaceAllStringFunc(text, func(token string) string {
+		word := strings.TrimSuffix(token, ".")
+		if month, ok := locale.months[strings.ToLower(word)]; ok {
+			return month.String() + token[len(word):]
+		}
+		if month, ok := locale.shortMonths[strings.ToLower(word)]; ok {
+			return month.String()[:3]
+		}
+		return token
+	})
+}
+
+// parseRelativeDate parses texts like "3 days ago", "vor 2 Stunden" or
+// "yesterday". The result is truncated to the unit, so repeated scrapes of
+// the same text give the same date.
+func parseRelativeDate(text string, locale dateLocale, now time.Time) (time.Time, bool) {
+	words := dateWordRegex.FindAllString(strings.ToLower(text), -1)
+	joined := " " + strings.Join(words, " ") + " "
+
+	hasWord := func(candidates []string) bool {
+		for _, candidate := range candidates {
+			if strings.Contains(joined, " "+candidate+" ") {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasWord(locale.yesterdayWords):
+		return startOfDay(now.AddDate(0, 0, -1)), true
+	case hasWord(locale.todayWords):
+		return startOfDay(now), true
+	case !hasWord(locale.agoMarkers):
+		if hasWord(locale.nowWords) {
+			return now.Truncate(time.Minute), true
+		}
+		return time.Time{}, false
+	}
+
+	for i, word := range words {
+		amount, unitWord := 0, ""
+		if match := amountAndUnitRegex.FindStringSubmatch(word); match != nil {
+			amount, _ = strconv.Atoi(match[1])
+			unitWord = match[2]
+		} else if i+1 < len(words) {
+			if n, err := strconv.Atoi(word); err == nil {
+				amount = n
+			} else if containsString(locale.oneWords, word) {
+				amount = 1
+			} else {
+				continue
+			}
+			unitWord = words[i+1]
+		} else {
+			continue
+		}
+
+		unit, ok := lookupRelativeUnit(locale, unitWord)
+		if !ok {
+			continue
+		}
+		return subtractDateUnits(now, unit, amount), true
+	}
+
+	return time.Time{}, false
+}
+
+func lookupRelativeUnit(locale dateLocale, word string) (dateUnit, bool) {
+	if unit, ok := locale.shortUnits[word]; ok {
+		return unit, true
+	}
+	for _, candidate := range locale.units {
+		if strings.HasPrefix(word, candidate.stem) {
+			return candidate.unit, true
+		}
+	}
+	return 0, false
+}
+
+func subtractDateUnits(now time.Time, unit dateUnit, amount int) time.Time {
+	switch unit {
+	case dateUnitSecond:
+		return now.Add(-time.Duration(amount) * time.Second).Truncate(time.Minute)
+	case dateUnitMinute:
+		return now.Add(-time.Duration(amount) * time.Minute).Truncate(time.Minute)
+	case dateUnitHour:
+		return now.Add(-time.Duration(amount) * time.Hour).Truncate(time.Hour)
+	case dateUnitDay:
+		return startOfDay(now.AddDate(0, 0, -amount))
+	case dateUnitWeek:
+		return startOfDay(now.AddDate(0, 0, -7*amount))
+	case dateUnitMonth:
+		return startOfDay(now.AddDate(0, -amount, 0))
+	default:
+		return startOfDay(now.AddDate(-amount, 0, 0))
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
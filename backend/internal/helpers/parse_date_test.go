@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"scrapeit/internal/models"
+	"testing"
+	"time"
+)
+
+func TestParseDateString(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 14, 35, 20, 0, time.UTC)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		text   string
+		config *models.DateParsingConfig
+		want   time.Time
+	}{
+		{"rfc3339", "2024-01-02T03:04:05Z", nil, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"iso date", "2023-12-24", nil, time.Date(2023, 12, 24, 0, 0, 0, 0, time.UTC)},
+		{"english month", "Jan 5, 2024", nil, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"us slashes", "02/03/2024", nil, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{"day first slashes", "02/03/2024", &models.DateParsingConfig{Locale: "de"}, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)},
+		{"german dots", "24.12.2023", &models.DateParsingConfig{Locale: "de"}, time.Date(2023, 12, 24, 0, 0, 0, 0, time.UTC)},
+		{"german month", "3. März 2024", &models.DateParsingConfig{Locale: "de"}, time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"french short month", "12 févr. 2024", &models.DateParsingConfig{Locale: "fr-FR"}, time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC)},
+		{"non breaking space", "Jan\u00A05, 2024", nil, time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"without year", "Mar 10", nil, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"without year in the future", "Dec 10", nil, time.Date(2023, 12, 10, 0, 0, 0, 0, time.UTC)},
+		{"custom layout", "2024|07|01", &models.DateParsingConfig{Layouts: []string{"2006|01|02"}}, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"timezone", "2024-01-02 10:00", &models.DateParsingConfig{Timezone: "Europe/Berlin"}, time.Date(2024, 1, 2, 10, 0, 0, 0, berlin)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateString(tt.text, tt.config, now)
+			if err != nil {
+				t.Fatalf("ParseDateString(%q) error: %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDateString(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateStringRelative(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 14, 35, 20, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		text   string
+		locale string
+		want   time.Time
+	}{
+		{"days ago", "3 days ago", "", time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"an hour ago", "an hour ago", "", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"short units", "5m ago", "", time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)},
+		{"weeks ago", "Posted 2 weeks ago", "", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"months ago", "1 month ago", "", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", "Yesterday, 10:15", "", time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"today", "today", "", time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"now", "just now", "", time.Date(2024, 3, 15, 14, 35, 0, 0, time.UTC)},
+		{"german", "vor 2 Stunden", "de", time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"german one", "vor einem Tag", "de", time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)},
+		{"french", "il y a 3 jours", "fr", time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC)},
+		{"spanish", "hace 1 año", "es", time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateString(tt.text, &models.DateParsingConfig{Locale: tt.locale}, now)
+			if err != nil {
+				t.Fatalf("ParseDateString(%q) error: %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDateString(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateStringErrors(t *testing.T) {
+	now := time.Date(2024, time.March, 15, 14, 35, 20, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		text   string
+		config *models.DateParsingConfig
+	}{
+		{"empty", "  ", nil},
+		{"no date", "call for price", nil},
+		{"relative disabled", "3 days ago", &models.DateParsingConfig{DisableRelativeDates: true}},
+		{"invalid timezone", "2024-01-02", &models.DateParsingConfig{Timezone: "Nowhere/Town"}},
+		{"layout mismatch", "2024-01-02", &models.DateParsingConfig{Layouts: []string{"02.01.2006"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ParseDateString(tt.text, tt.config, now); err == nil {
+				t.Errorf("ParseDateString(%q) = %v, want an error", tt.text, got)
+			}
+		})
+	}
+}
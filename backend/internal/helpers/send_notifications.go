@@ -46,8 +46,7 @@ func HandleNotifyResults(configs []models.NotificationConfig, group models.Scrap
 						break
 					}
 				}
-				if _, ok := foundValueByField.(float64); ok {
-					value := foundValueByField.(float64)
+				if value, ok := conditionValueToFloat(foundValueByField); ok {
 					switch condition.Operator {
 					case "=":
 						if value != condition.Value {
@@ -106,9 +105,13 @@ func HandleNotifyResults(configs []models.NotificationConfig, group models.Scrap
 								break
 							}
 						}
+						value := field.Value
+						if _, isList := ListValueToStrings(value); isList {
+							value = FormatFieldValue(value)
+						}
 						notificationResult.Fields = append(notificationResult.Fields, models.NotificationResultField{
 							FieldName: foundFieldName,
-							Value:     field.Value,
+							Value:     value,
 						})
 					}
 				}
@@ -160,6 +163,25 @@ func HandleNotifyResults(configs []models.NotificationConfig, group models.Scrap
 	}
 }
 
+// conditionValueToFloat converts a field value to the number notification
+// conditions compare against. Booleans are 1 or 0 and dates are unix
+// timestamps in seconds.
+func conditionValueToFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	if date, ok := DateValueToTime(value); ok {
+		return float64(date.Unix()), true
+	}
+	return 0, false
+}
+
 func sendNotification(
 	requestBody models.NotificationSearchResultRequestBody,
 ) {
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/xml"
+	"fmt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,6 +17,9 @@ type ScrapeGroup struct {
 	VersionTag    string             `json:"versionTag" bson:"versionTag"`
 	Created       primitive.DateTime `json:"created" bson:"created"`
 	Updated       primitive.DateTime `json:"updated" bson:"updated"`
+	// RespectRobotsTxt refuses URLs disallowed by the target's robots.txt
+	// and keeps its Crawl-delay.
+	RespectRobotsTxt bool `json:"respectRobotsTxt,omitempty" bson:"respectRobotsTxt,omitempty"`
 }
 
 type ArchivedScrapeGroup struct {
@@ -28,6 +32,9 @@ type ArchivedScrapeGroup struct {
 	VersionTag    string             `json:"versionTag" bson:"versionTag"`
 	Created       primitive.DateTime `json:"created" bson:"created"`
 	Updated       primitive.DateTime `json:"updated" bson:"updated"`
+	// RespectRobotsTxt refuses URLs disallowed by the target's robots.txt
+	// and keeps its Crawl-delay.
+	RespectRobotsTxt bool `json:"respectRobotsTxt,omitempty" bson:"respectRobotsTxt,omitempty"`
 }
 
 func (sg ScrapeGroup) GetEndpointById(id string) *Endpoint {
@@ -58,6 +65,43 @@ func (sg *ScrapeGroup) DeleteEndpoint(id string) {
 	sg.Endpoints = newEndpoints
 }
 
+// SecretMask replaces stored secrets in API responses. A secret sent back
+// as the mask keeps its stored value.
+const SecretMask = "********"
+
+// RedactSecrets masks the login passwords of the group's endpoints.
+func (sg *ScrapeGroup) RedactSecrets() {
+	for i := range sg.Endpoints {
+		sg.Endpoints[i].RedactSecrets()
+	}
+}
+
+func (sg *ArchivedScrapeGroup) RedactSecrets() {
+	for i := range sg.Endpoints {
+		sg.Endpoints[i].RedactSecrets()
+	}
+}
+
+func (e *Endpoint) RedactSecrets() {
+	if e.Login != nil && e.Login.Password != "" {
+		login := *e.Login
+		login.Password = SecretMask
+		e.Login = &login
+	}
+}
+
+// KeepSecrets replaces masked secrets with the ones of the stored endpoint.
+// Without a stored endpoint they are cleared.
+func (e *Endpoint) KeepSecrets(stored *Endpoint) {
+	if e.Login == nil || e.Login.Password != SecretMask {
+		return
+	}
+	e.Login.Password = ""
+	if stored != nil && stored.Login != nil {
+		e.Login.Password = stored.Login.Password
+	}
+}
+
 type ScrapeGroupLocal struct {
 	ID        string     `json:"id" bson:"id"`
 	Name      string     `json:"name" bson:"name"`
@@ -81,30 +125,361 @@ const (
 	FieldTypeImage  FieldType = "image"
 	FieldTypeLink   FieldType = "link"
 	FieldTypeNumber FieldType = "number"
+	// FieldTypeList collects every match of the selector into an array.
+	FieldTypeList FieldType = "list"
+	// FieldTypeDate and FieldTypeBoolean are parsed from the scraped text and
+	// stored as BSON dates and bools.
+	FieldTypeDate    FieldType = "date"
+	FieldTypeBoolean FieldType = "boolean"
 )
 
 type Endpoint struct {
 	ID                              string           `json:"id" bson:"id"`
 	Name                            string           `json:"name" bson:"name"`
 	URL                             string           `json:"url" bson:"url"`
+	Fetcher                         FetcherType      `json:"fetcher,omitempty" bson:"fetcher,omitempty"`
 	PaginationConfig                PaginationConfig `json:"paginationConfig" bson:"paginationConfig"`
 	MainElementSelector             string           `json:"mainElementSelector" bson:"mainElementSelector"`
+	MainElementSelectorLanguage     SelectorLanguage `json:"mainElementSelectorLanguage,omitempty" bson:"mainElementSelectorLanguage,omitempty"`
 	WithDetailedView                bool             `json:"withDetailedView" bson:"withDetailedView"`
 	DetailedViewTriggerSelector     string           `json:"detailedViewTriggerSelector" bson:"detailedViewTriggerSelector"`
+	DetailedViewTriggerLanguage     SelectorLanguage `json:"detailedViewTriggerSelectorLanguage,omitempty" bson:"detailedViewTriggerSelectorLanguage,omitempty"`
 	DetailedViewMainElementSelector string           `json:"detailedViewMainElementSelector" bson:"detailedViewMainElementSelector"`
+	DetailedViewMainElementLanguage SelectorLanguage `json:"detailedViewMainElementSelectorLanguage,omitempty" bson:"detailedViewMainElementSelectorLanguage,omitempty"`
 	DetailFieldSelectors            []FieldSelector  `json:"detailFieldSelectors" bson:"detailFieldSelectors"`
 	Interval                        string           `json:"interval,omitempty" bson:"interval,omitempty"`
 	Active                          bool             `json:"active,omitempty" bson:"active,omitempty"`
 	LastScraped                     time.Time        `json:"lastScraped,omitempty" bson:"lastScraped,omitempty"`
 	Status                          ScrapeStatus     `json:"status,omitempty" bson:"status,omitempty"`
+	LastRunStats                    *ScrapeRunStats  `json:"lastRunStats,omitempty" bson:"lastRunStats,omitempty"`
+	// NavigationSteps turn the endpoint into a multi level crawl starting at
+	// the main elements of the listing.
+	NavigationSteps []NavigationStep `json:"navigationSteps,omitempty" bson:"navigationSteps,omitempty"`
+	// Actions are run in order on every page opened by the browser fetcher,
+	// after it loaded and before its main element is waited for.
+	Actions []PageAction `json:"actions,omitempty" bson:"actions,omitempty"`
+	// Login signs in before the endpoint's pages are opened. Only the browser
+	// fetcher supports it.
+	Login *LoginConfig `json:"login,omitempty" bson:"login,omitempty"`
+	// Proxy routes the endpoint's browser pages and challenge solving through
+	// a proxy pool.
+	Proxy *EndpointProxyConfig `json:"proxy,omitempty" bson:"proxy,omitempty"`
+	// Retry loads pages and detail items again when they fail with a
+	// retryable error. Without it every page is loaded once.
+	Retry *RetryPolicy `json:"retry,omitempty" bson:"retry,omitempty"`
+	// ChallengeSolver configures how the browser fetcher gets past the
+	// site's bot challenge before loading pages.
+	ChallengeSolver *ChallengeSolverConfig `json:"challengeSolver,omitempty" bson:"challengeSolver,omitempty"`
+	// ArchiveHTML keeps the raw HTML of the elements every result was
+	// extracted from, so results can be extracted again after a selector
+	// was fixed. Multi level endpoints can not enable it.
+	ArchiveHTML bool `json:"archiveHtml,omitempty" bson:"archiveHtml,omitempty"`
+}
+
+// ChallengeSolverType selects what solves the bot challenges of an endpoint.
+// An empty value means FlareSolverr.
+type ChallengeSolverType string
+
+const (
+	ChallengeSolverFlareSolverr ChallengeSolverType = "flaresolverr"
+	// ChallengeSolverNone leaves challenges to the browser itself.
+	ChallengeSolverNone ChallengeSolverType = "none"
+	// ChallengeSolverCaptchaService sends captcha widgets to the service at
+	// CAPTCHA_SERVICE_URL.
+	ChallengeSolverCaptchaService ChallengeSolverType = "captchaService"
+)
+
+// ChallengeSolverConfig is only used when a page shows a challenge after
+// it loaded.
+type ChallengeSolverConfig struct {
+	Type ChallengeSolverType `json:"type,omitempty" bson:"type,omitempty"`
+	// TimeoutSeconds is how long the solver may take, 30 seconds by default.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" bson:"timeoutSeconds,omitempty"`
+	// Optional keeps the page when the solver is down or fails, instead of
+	// failing it.
+	Optional bool `json:"optional" bson:"optional"`
+}
+
+// RetryErrorClass names a kind of failure a retry policy can retry.
+type RetryErrorClass string
+
+const (
+	RetryErrorTimeout         RetryErrorClass = "timeout"
+	RetryErrorElementNotFound RetryErrorClass = "elementNotFound"
+	RetryErrorTooManyRequests RetryErrorClass = "http429"
+	RetryErrorUnavailable     RetryErrorClass = "http503"
+)
+
+// RetryPolicy retries failed page loads with exponential backoff. Zero
+// values fall back to 3 attempts, 1s initial and 30s max backoff, a
+// multiplier of 2 and 20% jitter.
+type RetryPolicy struct {
+	MaxAttempts      int     `json:"maxAttempts,omitempty" bson:"maxAttempts,omitempty"`
+	InitialBackoffMs int     `json:"initialBackoffMs,omitempty" bson:"initialBackoffMs,omitempty"`
+	MaxBackoffMs     int     `json:"maxBackoffMs,omitempty" bson:"maxBackoffMs,omitempty"`
+	Multiplier       float64 `json:"multiplier,omitempty" bson:"multiplier,omitempty"`
+	// Jitter shifts every backoff randomly by up to this fraction of it.
+	Jitter float64 `json:"jitter,omitempty" bson:"jitter,omitempty"`
+	// RetryOn limits the retried failures. All classes are retried when it
+	// is empty.
+	RetryOn []RetryErrorClass `json:"retryOn,omitempty" bson:"retryOn,omitempty"`
+}
+
+type ProxyProtocol string
+
+const (
+	ProxyProtocolHTTP   ProxyProtocol = "http"
+	ProxyProtocolHTTPS  ProxyProtocol = "https"
+	ProxyProtocolSOCKS5 ProxyProtocol = "socks5"
+)
+
+type Proxy struct {
+	ID       string        `json:"id" bson:"id"`
+	Protocol ProxyProtocol `json:"protocol" bson:"protocol"`
+	Host     string        `json:"host" bson:"host"`
+	Port     int           `json:"port" bson:"port"`
+	Username string        `json:"username,omitempty" bson:"username,omitempty"`
+	Password string        `json:"password,omitempty" bson:"password,omitempty"`
+}
+
+// Server returns the proxy address without credentials, e.g.
+// socks5://127.0.0.1:1080.
+func (p Proxy) Server() string {
+	protocol := p.Protocol
+	if protocol == "" {
+		protocol = ProxyProtocolHTTP
+	}
+	return fmt.Sprintf("%s://%s:%d", protocol, p.Host, p.Port)
+}
+
+type ProxyPool struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name    string             `json:"name" bson:"name"`
+	Proxies []Proxy            `json:"proxies" bson:"proxies"`
+	Created primitive.DateTime `json:"created" bson:"created"`
+	Updated primitive.DateTime `json:"updated" bson:"updated"`
+}
+
+// RedactSecrets masks the proxy passwords of the pool.
+func (p *ProxyPool) RedactSecrets() {
+	for i := range p.Proxies {
+		if p.Proxies[i].Password != "" {
+			p.Proxies[i].Password = SecretMask
+		}
+	}
+}
+
+// KeepSecrets replaces masked proxy passwords with the stored password of
+// the proxy with the same ID. Without one they are cleared.
+func (p *ProxyPool) KeepSecrets(stored *ProxyPool) {
+	for i, proxy := range p.Proxies {
+		if proxy.Password != SecretMask {
+			continue
+		}
+		p.Proxies[i].Password = ""
+		if stored == nil {
+			continue
+		}
+		for _, storedProxy := range stored.Proxies {
+			if storedProxy.ID == proxy.ID {
+				p.Proxies[i].Password = storedProxy.Password
+				break
+			}
+		}
+	}
+}
+
+// Cookie is a browser cookie as FlareSolverr returns it. Expiry is in unix
+// seconds, session cookies have none.
+type Cookie struct {
+	Domain   string `json:"domain" bson:"domain"`
+	Expiry   int64  `json:"expiry" bson:"expiry"`
+	HttpOnly bool   `json:"httpOnly" bson:"httpOnly"`
+	Name     string `json:"name" bson:"name"`
+	Path     string `json:"path" bson:"path"`
+	SameSite string `json:"sameSite" bson:"sameSite"`
+	Secure   bool   `json:"secure" bson:"secure"`
+	Value    string `json:"value" bson:"value"`
+}
+
+// CookieSessionKind tells stored challenge cookies from login sessions.
+type CookieSessionKind string
+
+const (
+	CookieSessionChallenge CookieSessionKind = "challenge"
+	CookieSessionLogin     CookieSessionKind = "login"
+)
+
+// CookieSession is a set of cookies shared by every backend instance.
+// Challenge cookies are keyed by base URL, plus the proxy that solved them,
+// login sessions by their session key.
+type CookieSession struct {
+	Key       string            `json:"key" bson:"_id"`
+	Kind      CookieSessionKind `json:"kind" bson:"kind"`
+	Domain    string            `json:"domain" bson:"domain"`
+	Cookies   []Cookie          `json:"cookies" bson:"cookies"`
+	UserAgent string            `json:"userAgent,omitempty" bson:"userAgent,omitempty"`
+	Updated   time.Time         `json:"updated" bson:"updated"`
+	// ExpiresAt is the end of the domain's TTL or the first cookie expiry,
+	// whichever comes first. Sessions without either do not expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
 }
 
+// CookieTTLRule sets how long cookies of a domain and its subdomains are
+// used before they are fetched again.
+type CookieTTLRule struct {
+	Domain     string `json:"domain" bson:"_id"`
+	TTLSeconds int    `json:"ttlSeconds" bson:"ttlSeconds"`
+}
+
+// PageFailure is a page that failed to load or to show its main element,
+// with a screenshot and its HTML stored in GridFS for debugging.
+type PageFailure struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	GroupID      string              `json:"groupId,omitempty" bson:"groupId,omitempty"`
+	EndpointID   string              `json:"endpointId,omitempty" bson:"endpointId,omitempty"`
+	RunID        string              `json:"runId,omitempty" bson:"runId,omitempty"`
+	URL          string              `json:"url" bson:"url"`
+	Kind         string              `json:"kind,omitempty" bson:"kind,omitempty"`
+	Error        string              `json:"error" bson:"error"`
+	ScreenshotID *primitive.ObjectID `json:"screenshotId,omitempty" bson:"screenshotId,omitempty"`
+	HTMLID       *primitive.ObjectID `json:"htmlId,omitempty" bson:"htmlId,omitempty"`
+	Created      primitive.DateTime  `json:"created" bson:"created"`
+}
+
+// ProxyRotation decides how often an endpoint switches to another proxy of
+// its pool.
+type ProxyRotation string
+
+const (
+	ProxyRotationPerRun       ProxyRotation = "perRun"
+	ProxyRotationPerPage      ProxyRotation = "perPage"
+	ProxyRotationStickyDomain ProxyRotation = "stickyDomain"
+)
+
+type EndpointProxyConfig struct {
+	PoolID   string        `json:"poolId" bson:"poolId"`
+	Rotation ProxyRotation `json:"rotation,omitempty" bson:"rotation,omitempty"`
+}
+
+// LoginConfig describes how to sign in to a site. The session cookies are
+// stored and reused until they expire or the logged in selector goes missing.
+type LoginConfig struct {
+	URL      string `json:"url" bson:"url"`
+	Username string `json:"username,omitempty" bson:"username,omitempty"`
+	// Password is only accepted on write, responses carry SecretMask.
+	Password string `json:"password,omitempty" bson:"password,omitempty"`
+	// Steps fill in and submit the login form. {{username}} and {{password}}
+	// in a step's value are replaced with the credentials.
+	Steps                    []PageAction     `json:"steps" bson:"steps"`
+	LoggedInSelector         string           `json:"loggedInSelector,omitempty" bson:"loggedInSelector,omitempty"`
+	LoggedInSelectorLanguage SelectorLanguage `json:"loggedInSelectorLanguage,omitempty" bson:"loggedInSelectorLanguage,omitempty"`
+	// SessionKey lets endpoints share a session. It defaults to the login
+	// URL's domain and the username, so one session is kept per account and
+	// domain.
+	SessionKey string `json:"sessionKey,omitempty" bson:"sessionKey,omitempty"`
+	// SessionCookieNames are the cookies holding the session. The stored
+	// session ends when the first of them expires, without names only a
+	// missing logged in selector ends it.
+	SessionCookieNames []string `json:"sessionCookieNames,omitempty" bson:"sessionCookieNames,omitempty"`
+}
+
+// NavigationStep is one hop of a multi level crawl. The trigger selector's
+// href is followed from every element of the previous level, and the main
+// element selector finds the elements of the next level on the opened page.
+type NavigationStep struct {
+	ID                          string           `json:"id" bson:"id"`
+	Name                        string           `json:"name,omitempty" bson:"name,omitempty"`
+	TriggerSelector             string           `json:"triggerSelector" bson:"triggerSelector"`
+	TriggerSelectorLanguage     SelectorLanguage `json:"triggerSelectorLanguage,omitempty" bson:"triggerSelectorLanguage,omitempty"`
+	MainElementSelector         string           `json:"mainElementSelector" bson:"mainElementSelector"`
+	MainElementSelectorLanguage SelectorLanguage `json:"mainElementSelectorLanguage,omitempty" bson:"mainElementSelectorLanguage,omitempty"`
+	// Multiple continues the crawl from every element matching the main
+	// element selector instead of only the first one, e.g. for sub-category
+	// pages listing several items.
+	Multiple bool `json:"multiple,omitempty" bson:"multiple,omitempty"`
+}
+
+type PageActionType string
+
+const (
+	PageActionTypeClick           PageActionType = "click"
+	PageActionTypeType            PageActionType = "type"
+	PageActionTypeSelect          PageActionType = "select"
+	PageActionTypeWaitForSelector PageActionType = "waitForSelector"
+	PageActionTypeWait            PageActionType = "wait"
+	PageActionTypePressKey        PageActionType = "pressKey"
+	PageActionTypeScroll          PageActionType = "scroll"
+	PageActionTypeEvalJS          PageActionType = "evalJs"
+)
+
+// PageActionScope limits an action to the endpoint's own pages or to the
+// detail and navigation pages opened from them. Empty means every page.
+type PageActionScope string
+
+const (
+	PageActionScopeMain   PageActionScope = "main"
+	PageActionScopeDetail PageActionScope = "detail"
+)
+
+// PageAction is a scripted step run on a page before elements are extracted,
+// e.g. to dismiss a cookie banner or fill in a search box.
+type PageAction struct {
+	ID               string           `json:"id" bson:"id"`
+	Type             PageActionType   `json:"type" bson:"type"`
+	Selector         string           `json:"selector,omitempty" bson:"selector,omitempty"`
+	SelectorLanguage SelectorLanguage `json:"selectorLanguage,omitempty" bson:"selectorLanguage,omitempty"`
+	// Value is the text to type, the option text to select, the key to press
+	// or the script to evaluate.
+	Value string `json:"value,omitempty" bson:"value,omitempty"`
+	// Milliseconds is how long a wait action sleeps.
+	Milliseconds   int             `json:"milliseconds,omitempty" bson:"milliseconds,omitempty"`
+	TimeoutSeconds int             `json:"timeoutSeconds,omitempty" bson:"timeoutSeconds,omitempty"`
+	Optional       bool            `json:"optional,omitempty" bson:"optional,omitempty"`
+	Scope          PageActionScope `json:"scope,omitempty" bson:"scope,omitempty"`
+}
+
+// ScrapeRunStats describes what a single scrape run of an endpoint did.
+type ScrapeRunStats struct {
+	// RunID tags the page failures captured during the run.
+	RunID        string `json:"runId,omitempty" bson:"runId,omitempty"`
+	PagesVisited int    `json:"pagesVisited" bson:"pagesVisited"`
+	// PagesSkipped counts the listing pages that failed to load and were
+	// skipped.
+	PagesSkipped int `json:"pagesSkipped,omitempty" bson:"pagesSkipped,omitempty"`
+	// FallbackMatches counts per field ID the results whose value came from
+	// a fallback selector instead of the primary one.
+	FallbackMatches map[string]int `json:"fallbackMatches,omitempty" bson:"fallbackMatches,omitempty"`
+	// Retries counts per error class the page loads that were retried.
+	Retries map[RetryErrorClass]int `json:"retries,omitempty" bson:"retries,omitempty"`
+	// RetriesExhausted counts the page loads that still failed after the
+	// last attempt.
+	RetriesExhausted int `json:"retriesExhausted,omitempty" bson:"retriesExhausted,omitempty"`
+	// Cancelled is set when the run was cancelled before it finished, its
+	// results are the ones scraped until then.
+	Cancelled bool `json:"cancelled,omitempty" bson:"cancelled,omitempty"`
+}
+
+// FetcherType selects how an endpoint's pages are loaded. An empty value
+// means the browser is used. With json_api the URL returns JSON and all
+// selectors of the endpoint are JSONPath expressions.
+type FetcherType string
+
+const (
+	FetcherTypeBrowser FetcherType = "browser"
+	FetcherTypeHTTP    FetcherType = "http"
+	FetcherTypeJSONAPI FetcherType = "json_api"
+)
+
 type PaginationConfigType string
 
 const (
 	PaginationConfigTypeNone         PaginationConfigType = "none"
 	PaginationConfigTypeUrlParameter PaginationConfigType = "url_parameter"
 	PaginationConfigTypePath         PaginationConfigType = "url_path"
+	PaginationConfigTypeNextButton   PaginationConfigType = "next_button"
+	PaginationConfigTypeInfinite     PaginationConfigType = "infinite_scroll"
+	PaginationConfigTypeLoadMore     PaginationConfigType = "load_more"
 )
 
 type PaginationConfig struct {
@@ -114,6 +489,22 @@ type PaginationConfig struct {
 	End              int     `json:"end" bson:"end"`
 	Step             int     `json:"step" bson:"step"`
 	UrlRegexToInsert *string `json:"urlRegexToInsert" bson:"urlRegexToInsert"`
+	// OpenEnded ignores End for url pagination and keeps going until a page
+	// has no main elements or only results already seen in the same run.
+	OpenEnded bool `json:"openEnded,omitempty" bson:"openEnded,omitempty"`
+	// NextButtonSelector is clicked to reach the following page when Type is
+	// next_button. MaxPages caps how many pages next_button and open ended
+	// pagination visit, 0 uses the default.
+	NextButtonSelector         string           `json:"nextButtonSelector,omitempty" bson:"nextButtonSelector,omitempty"`
+	NextButtonSelectorLanguage SelectorLanguage `json:"nextButtonSelectorLanguage,omitempty" bson:"nextButtonSelectorLanguage,omitempty"`
+	MaxPages                   int              `json:"maxPages,omitempty" bson:"maxPages,omitempty"`
+	// LoadMoreSelector is clicked to grow the listing when Type is load_more.
+	// Infinite scroll and load more stop once the main element count stops
+	// growing, TargetItemCount is reached or TimeBudgetSeconds has passed.
+	LoadMoreSelector         string           `json:"loadMoreSelector,omitempty" bson:"loadMoreSelector,omitempty"`
+	LoadMoreSelectorLanguage SelectorLanguage `json:"loadMoreSelectorLanguage,omitempty" bson:"loadMoreSelectorLanguage,omitempty"`
+	TargetItemCount          int              `json:"targetItemCount,omitempty" bson:"targetItemCount,omitempty"`
+	TimeBudgetSeconds        int              `json:"timeBudgetSeconds,omitempty" bson:"timeBudgetSeconds,omitempty"`
 }
 
 type ScrapeStatus string
@@ -131,15 +522,114 @@ const (
 	SelectorStatusNew         SelectorStatusValue = "new"
 )
 
+type FieldScope string
+
+const (
+	FieldScopePreview FieldScope = "preview"
+	FieldScopeDetail  FieldScope = "detail"
+)
+
+// SelectorFallback is an alternative selector for a field, used when a site
+// changes and the primary selector stops matching.
+type SelectorFallback struct {
+	Selector             string           `json:"selector" bson:"selector"`
+	SelectorLanguage     SelectorLanguage `json:"selectorLanguage,omitempty" bson:"selectorLanguage,omitempty"`
+	Regex                string           `json:"regex" bson:"regex"`
+	RegexMatchIndexToUse int              `json:"regexMatchIndexToUse" bson:"regexMatchIndexToUse"`
+	AttributeToGet       string           `json:"attributeToGet" bson:"attributeToGet"`
+}
+
+type TransformType string
+
+const (
+	// TransformTypeTrim trims the value and collapses inner whitespace.
+	TransformTypeTrim      TransformType = "trim"
+	TransformTypeReplace   TransformType = "replace"
+	TransformTypeLowercase TransformType = "lowercase"
+	TransformTypePrefix    TransformType = "prefix"
+	TransformTypeSuffix    TransformType = "suffix"
+	// TransformTypeSplit splits by Separator and keeps the part at Index,
+	// negative indexes count from the end.
+	TransformTypeSplit    TransformType = "split"
+	TransformTypeMultiply TransformType = "multiply"
+	TransformTypeDivide   TransformType = "divide"
+	// TransformTypeMap replaces the value with its entry in Mapping.
+	TransformTypeMap TransformType = "map"
+	// TransformTypeDefault sets Value when the value is empty.
+	TransformTypeDefault TransformType = "default"
+)
+
+// FieldTransform is one step of the transforms applied to a scraped value
+// before it is typed. Which options are used depends on the type.
+type FieldTransform struct {
+	Type      TransformType     `json:"type" bson:"type"`
+	Value     string            `json:"value,omitempty" bson:"value,omitempty"`
+	From      string            `json:"from,omitempty" bson:"from,omitempty"`
+	To        string            `json:"to,omitempty" bson:"to,omitempty"`
+	Separator string            `json:"separator,omitempty" bson:"separator,omitempty"`
+	Index     int               `json:"index,omitempty" bson:"index,omitempty"`
+	Factor    float64           `json:"factor,omitempty" bson:"factor,omitempty"`
+	Mapping   map[string]string `json:"mapping,omitempty" bson:"mapping,omitempty"`
+}
+
+// TransformStep is the output of a single transform, shown when testing an
+// endpoint.
+type TransformStep struct {
+	Type   TransformType `json:"type"`
+	Output interface{}   `json:"output"`
+}
+
+// DateParsingConfig configures how the text of a date field is parsed.
+type DateParsingConfig struct {
+	// Layouts are Go time layouts tried in order, e.g. "02.01.2006". When
+	// empty a set of common layouts is used.
+	Layouts []string `json:"layouts,omitempty" bson:"layouts,omitempty"`
+	// Locale is used for month names and relative dates (en, de, fr, es).
+	Locale string `json:"locale,omitempty" bson:"locale,omitempty"`
+	// Timezone is an IANA time zone name, dates are parsed in UTC by default.
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	// DisableRelativeDates turns off parsing of texts like "3 days ago".
+	DisableRelativeDates bool `json:"disableRelativeDates,omitempty" bson:"disableRelativeDates,omitempty"`
+}
+
+// BooleanParsingConfig configures how the text of a boolean field is parsed.
+// Without true values any non empty text that is not a false value is true.
+type BooleanParsingConfig struct {
+	TrueValues  []string `json:"trueValues,omitempty" bson:"trueValues,omitempty"`
+	FalseValues []string `json:"falseValues,omitempty" bson:"falseValues,omitempty"`
+}
+
+// SelectorLanguage is the language a selector is written in. An empty value
+// means css.
+type SelectorLanguage string
+
+const (
+	SelectorLanguageCSS   SelectorLanguage = "css"
+	SelectorLanguageXPath SelectorLanguage = "xpath"
+)
+
 type FieldSelector struct {
-	ID                   string              `json:"id" bson:"id"`
-	FieldID              string              `json:"fieldId" bson:"fieldId"`
-	Selector             string              `json:"selector" bson:"selector"`
-	Regex                string              `json:"regex" bson:"regex"`
-	AttributeToGet       string              `json:"attributeToGet" bson:"attributeToGet"`
-	RegexMatchIndexToUse int                 `json:"regexMatchIndexToUse" bson:"regexMatchIndexToUse"`
-	SelectorStatus       SelectorStatusValue `json:"selectorStatus" bson:"selectorStatus"`
-	LockedForEdit        bool                `json:"lockedForEdit" bson:"lockedForEdit"`
+	ID                   string                `json:"id" bson:"id"`
+	FieldID              string                `json:"fieldId" bson:"fieldId"`
+	Selector             string                `json:"selector" bson:"selector"`
+	SelectorLanguage     SelectorLanguage      `json:"selectorLanguage,omitempty" bson:"selectorLanguage,omitempty"`
+	DateParsing          *DateParsingConfig    `json:"dateParsing,omitempty" bson:"dateParsing,omitempty"`
+	BooleanParsing       *BooleanParsingConfig `json:"booleanParsing,omitempty" bson:"booleanParsing,omitempty"`
+	Transforms           []FieldTransform      `json:"transforms,omitempty" bson:"transforms,omitempty"`
+	Regex                string                `json:"regex" bson:"regex"`
+	AttributeToGet       string                `json:"attributeToGet" bson:"attributeToGet"`
+	RegexMatchIndexToUse int                   `json:"regexMatchIndexToUse" bson:"regexMatchIndexToUse"`
+	SelectorStatus       SelectorStatusValue   `json:"selectorStatus" bson:"selectorStatus"`
+	LockedForEdit        bool                  `json:"lockedForEdit" bson:"lockedForEdit"`
+	// Fallbacks are tried in order when the selector yields no value.
+	Fallbacks []SelectorFallback `json:"fallbacks,omitempty" bson:"fallbacks,omitempty"`
+	// Level is the navigation level the field is extracted from in multi
+	// level crawls, 0 being the listing and n the element reached by
+	// NavigationSteps[n-1].
+	Level int `json:"level,omitempty" bson:"level,omitempty"`
+	// Scope selects the element the field is extracted from on previews with
+	// details endpoints. Fields without a scope use the detail element.
+	Scope FieldScope `json:"scope,omitempty" bson:"scope,omitempty"`
 }
 
 type SearchConfig struct {
@@ -150,11 +640,12 @@ type SearchConfig struct {
 }
 
 type FieldSelectorsResponse struct {
-	Field                string `json:"field" bson:"field"`
-	Selector             string `json:"selector" bson:"selector"`
-	Regex                string `json:"regex" bson:"regex"`
-	RegexMatchIndexToUse int    `json:"regexMatchIndexToUse" bson:"regexMatchIndexToUse"`
-	AttributeToGet       string `json:"attributeToGet" bson:"attributeToGet"`
+	Field                string           `json:"field" bson:"field"`
+	Selector             string           `json:"selector" bson:"selector"`
+	SelectorLanguage     SelectorLanguage `json:"selectorLanguage" bson:"selectorLanguage"`
+	Regex                string           `json:"regex" bson:"regex"`
+	RegexMatchIndexToUse int              `json:"regexMatchIndexToUse" bson:"regexMatchIndexToUse"`
+	AttributeToGet       string           `json:"attributeToGet" bson:"attributeToGet"`
 }
 
 type FieldToExtractSelectorsFor struct {
@@ -180,6 +671,24 @@ type ScrapeResult struct {
 	GroupVersionTag     string               `json:"groupVersionTag" bson:"groupVersionTag"`
 }
 
+// ElementSnapshot is the raw HTML a scrape result was extracted from. Only
+// the latest snapshot of a result is kept.
+type ElementSnapshot struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GroupID    primitive.ObjectID `json:"groupId" bson:"groupId"`
+	EndpointID string             `json:"endpointId" bson:"endpointId"`
+	RunID      string             `json:"runId" bson:"runId"`
+	// ResultHash is the unique hash of the result extracted from the HTML.
+	ResultHash string `json:"resultHash" bson:"resultHash"`
+	// Link is the page the result links to, set for detail elements.
+	Link string `json:"link,omitempty" bson:"link,omitempty"`
+	// HTML is the main element, the preview for previews with details.
+	HTML string `json:"html" bson:"html"`
+	// DetailHTML is the detail page element of previews with details.
+	DetailHTML string             `json:"detailHtml,omitempty" bson:"detailHtml,omitempty"`
+	Created    primitive.DateTime `json:"created" bson:"created"`
+}
+
 type ScrapeResultDetail struct {
 	ID      string      `json:"id" bson:"id"`
 	FieldID string      `json:"fieldId" bson:"fieldId"`
@@ -232,6 +741,12 @@ type ScrapeResultDetailTest struct {
 	Value        interface{} `json:"value"`
 	RegexMatches []string    `json:"regexMatches"`
 	RawData      string      `json:"rawData"`
+	ParseError   string      `json:"parseError,omitempty"`
+	// MatchedSelectorIndex is 0 when the primary selector yielded the value,
+	// n for Fallbacks[n-1] and -1 when no selector yielded a value.
+	MatchedSelectorIndex int `json:"matchedSelectorIndex"`
+	// TransformSteps holds the value after each of the selector's transforms.
+	TransformSteps []TransformStep `json:"transformSteps,omitempty"`
 }
 
 type FieldChangeType string
@@ -0,0 +1,46 @@
+package models
+
+import "testing"
+
+func TestRedactSecrets(t *testing.T) {
+	stored := Endpoint{ID: "e1", Login: &LoginConfig{Username: "jane", Password: "hunter2"}}
+	group := ScrapeGroup{Endpoints: []Endpoint{stored, {ID: "e2"}}}
+
+	group.RedactSecrets()
+	if got := group.Endpoints[0].Login.Password; got != SecretMask {
+		t.Errorf("redacted password = %q, want the mask", got)
+	}
+	if group.Endpoints[1].Login != nil {
+		t.Error("endpoint without login got one")
+	}
+	if stored.Login.Password != "hunter2" {
+		t.Error("RedactSecrets() changed the login shared with the stored endpoint")
+	}
+}
+
+func TestKeepSecrets(t *testing.T) {
+	stored := &Endpoint{Login: &LoginConfig{Password: "hunter2"}}
+
+	tests := []struct {
+		name     string
+		password string
+		stored   *Endpoint
+		want     string
+	}{
+		{"mask keeps the stored password", SecretMask, stored, "hunter2"},
+		{"new password replaces it", "correct horse", stored, "correct horse"},
+		{"cleared password", "", stored, ""},
+		{"mask without stored endpoint", SecretMask, nil, ""},
+		{"mask without stored login", SecretMask, &Endpoint{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := Endpoint{Login: &LoginConfig{Password: tt.password}}
+			endpoint.KeepSecrets(tt.stored)
+			if got := endpoint.Login.Password; got != tt.want {
+				t.Errorf("password = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
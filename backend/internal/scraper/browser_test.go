@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"os"
+	"scrapeit/internal/models"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// testBrowser starts a headless Chrome for the test. The binary is taken
+// from CHROME_BIN or the usual install locations, the test is skipped when
+// there is none or with -short.
+func testBrowser(t *testing.T) *rod.Browser {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping browser test in short mode")
+	}
+	bin := os.Getenv("CHROME_BIN")
+	if bin == "" {
+		path, found := launcher.LookPath()
+		if !found {
+			t.Skip("no Chrome found, set CHROME_BIN to run browser tests")
+		}
+		bin = path
+	}
+
+	l := launcher.New().Bin(bin).Headless(true).NoSandbox(true)
+	controlURL, err := l.Launch()
+	if err != nil {
+		t.Fatalf("error launching Chrome: %v", err)
+	}
+	browser := rod.New().ControlURL(controlURL)
+	if err := browser.Connect(); err != nil {
+		l.Kill()
+		t.Fatalf("error connecting to Chrome: %v", err)
+	}
+	t.Cleanup(func() {
+		browser.Close()
+		l.Cleanup()
+	})
+	return browser
+}
+
+// pageFetcher opens plain browser pages, without the login, proxy and
+// challenge handling of browserFetcher.
+type pageFetcher struct {
+	browser *rod.Browser
+}
+
+func (f *pageFetcher) Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	page, err := f.browser.Page(proto.TargetCreateTarget{URL: url})
+	if err != nil {
+		return nil, err
+	}
+	if err := page.WaitLoad(); err != nil {
+		page.Close()
+		return nil, err
+	}
+	return &browserDocument{page: page}, nil
+}
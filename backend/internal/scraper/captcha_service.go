@@ -0,0 +1,214 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"scrapeit/internal/models"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// captchaServicePollInterval is how often a submitted captcha is checked.
+const captchaServicePollInterval = 5 * time.Second
+
+// captchaServiceSolver solves captcha widgets through a service speaking the
+// 2captcha API (in.php to submit, res.php to poll), enters the token on the
+// page and submits it.
+type captchaServiceSolver struct {
+	baseURL      string
+	apiKey       string
+	timeout      time.Duration
+	pollInterval time.Duration
+	client       *http.Client
+}
+
+func newCaptchaServiceSolver(baseURL string, apiKey string, timeout time.Duration) *captchaServiceSolver {
+	return &captchaServiceSolver{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		apiKey:       apiKey,
+		timeout:      timeout,
+		pollInterval: captchaServicePollInterval,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// captchaServiceMethods maps challenges to the service's method names.
+var captchaServiceMethods = map[ChallengeKind]string{
+	ChallengeTurnstile: "turnstile",
+	ChallengeRecaptcha: "userrecaptcha",
+	ChallengeHCaptcha:  "hcaptcha",
+}
+
+type captchaServiceResponse struct {
+	Status  int    `json:"status"`
+	Request string `json:"request"`
+}
+
+func (s *captchaServiceSolver) Solve(ctx context.Context, page *rod.Page, pageURL string, challenge Challenge, proxy *models.Proxy) (UserAgentWithCookies, error) {
+	if s.baseURL == "" || s.apiKey == "" {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: pageURL, Err: fmt.Errorf("CAPTCHA_SERVICE_URL and CAPTCHA_SERVICE_API_KEY have to be set")}
+	}
+	method, ok := captchaServiceMethods[challenge.Kind]
+	if !ok || challenge.SiteKey == "" {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: pageURL, Err: fmt.Errorf("the captcha service cannot solve %s challenges without a site key", challenge.Kind)}
+	}
+
+	solveCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	token, err := s.solveToken(solveCtx, method, challenge.SiteKey, pageURL)
+	if err != nil {
+		if ctx.Err() != nil {
+			return UserAgentWithCookies{}, ctx.Err()
+		}
+		if isTimeout(err) {
+			return UserAgentWithCookies{}, &PageError{Kind: PageErrorTimeout, URL: pageURL, Err: fmt.Errorf("captcha service: %w", err)}
+		}
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: pageURL, Err: fmt.Errorf("captcha service: %w", err)}
+	}
+
+	if err := submitCaptchaToken(ctx, page, challenge.Kind, token); err != nil {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorBlocked, URL: pageURL, Err: fmt.Errorf("error submitting captcha token: %w", err)}
+	}
+
+	cookies, err := page.Cookies(nil)
+	if err != nil {
+		return UserAgentWithCookies{}, fmt.Errorf("error reading cookies after solving captcha: %w", err)
+	}
+	userAgent, err := page.Eval(`() => navigator.userAgent`)
+	if err != nil {
+		return UserAgentWithCookies{}, fmt.Errorf("error reading user agent after solving captcha: %w", err)
+	}
+	return UserAgentWithCookies{Cookie: fromNetworkCookies(cookies), UserAgent: userAgent.Value.Str()}, nil
+}
+
+// solveToken submits the captcha and polls until the service returns its
+// token.
+func (s *captchaServiceSolver) solveToken(ctx context.Context, method string, siteKey string, pageURL string) (string, error) {
+	params := url.Values{
+		"key":     {s.apiKey},
+		"method":  {method},
+		"pageurl": {pageURL},
+		"json":    {"1"},
+	}
+	if method == "userrecaptcha" {
+		params.Set("googlekey", siteKey)
+	} else {
+		params.Set("sitekey", siteKey)
+	}
+
+	submitted, err := s.call(ctx, http.MethodPost, "/in.php", params)
+	if err != nil {
+		return "", err
+	}
+	if submitted.Status != 1 {
+		return "", fmt.Errorf("submitting captcha failed: %s", submitted.Request)
+	}
+
+	poll := url.Values{
+		"key":    {s.apiKey},
+		"action": {"get"},
+		"id":     {submitted.Request},
+		"json":   {"1"},
+	}
+	for {
+		select {
+		case <-time.After(s.pollInterval):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		result, err := s.call(ctx, http.MethodGet, "/res.php", poll)
+		if err != nil {
+			return "", err
+		}
+		if result.Status == 1 {
+			return result.Request, nil
+		}
+		if result.Request != "CAPCHA_NOT_READY" {
+			return "", fmt.Errorf("solving captcha failed: %s", result.Request)
+		}
+	}
+}
+
+func (s *captchaServiceSolver) call(ctx context.Context, method string, path string, params url.Values) (captchaServiceResponse, error) {
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, s.baseURL+path, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, s.baseURL+path+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return captchaServiceResponse{}, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return captchaServiceResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return captchaServiceResponse{}, &HTTPStatusError{URL: s.baseURL + path, StatusCode: resp.StatusCode}
+	}
+
+	var response captchaServiceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return captchaServiceResponse{}, fmt.Errorf("error decoding %s response: %w", path, err)
+	}
+	return response, nil
+}
+
+// submitCaptchaToken enters the token in the widget's response field and
+// hands it to the widget's callback, or submits the widget's form when it
+// has none.
+func submitCaptchaToken(ctx context.Context, page *rod.Page, kind ChallengeKind, token string) error {
+	fields := map[ChallengeKind]string{
+		ChallengeTurnstile: "cf-turnstile-response",
+		ChallengeRecaptcha: "g-recaptcha-response",
+		ChallengeHCaptcha:  "h-captcha-response",
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	waitNavigation := page.Context(waitCtx).WaitNavigation(proto.PageLifecycleEventNameLoad)
+
+	res, err := page.Eval(`(field, token) => {
+		let input = null
+		document.querySelectorAll("[name='" + field + "']").forEach(el => {
+			el.value = token
+			input = el
+		})
+		const widget = document.querySelector(".cf-turnstile, .g-recaptcha, .h-captcha")
+		const callback = widget && widget.getAttribute("data-callback")
+		if (callback && typeof window[callback] === "function") {
+			window[callback](token)
+			return "callback"
+		}
+		const form = (input && input.form) || (widget && widget.closest("form"))
+		if (form) {
+			form.submit()
+			return "form"
+		}
+		return ""
+	}`, fields[kind], token)
+	if err != nil {
+		return err
+	}
+	if res.Value.Str() == "" {
+		return fmt.Errorf("no callback or form to hand the token to")
+	}
+
+	// A callback does not always navigate, the wait then just times out.
+	waitNavigation()
+	return nil
+}
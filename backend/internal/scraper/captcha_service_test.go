@@ -0,0 +1,245 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// captchaService fakes the 2captcha API. in.php answers submit, res.php
+// answers every poll with poll(n) for the nth poll.
+type captchaService struct {
+	submit string
+	poll   func(n int32) string
+	polls  atomic.Int32
+	params chan map[string]string
+}
+
+func newCaptchaService(t *testing.T, submit string, poll func(n int32) string) (*captchaService, *captchaServiceSolver) {
+	t.Helper()
+	service := &captchaService{submit: submit, poll: poll, params: make(chan map[string]string, 1)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/in.php":
+			if r.Method != http.MethodPost {
+				http.Error(w, "in.php takes a POST", http.StatusMethodNotAllowed)
+				return
+			}
+			params := map[string]string{}
+			for key := range r.PostForm {
+				params[key] = r.PostForm.Get(key)
+			}
+			service.params <- params
+			fmt.Fprint(w, service.submit)
+		case "/res.php":
+			if r.URL.Query().Get("id") != "4242" || r.URL.Query().Get("action") != "get" {
+				fmt.Fprint(w, `{"status":0,"request":"ERROR_WRONG_CAPTCHA_ID"}`)
+				return
+			}
+			fmt.Fprint(w, service.poll(service.polls.Add(1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	solver := newCaptchaServiceSolver(server.URL+"/", "secret", time.Second)
+	solver.pollInterval = time.Millisecond
+	return service, solver
+}
+
+const submitted = `{"status":1,"request":"4242"}`
+
+func notReadyUntil(ready int32, token string) func(n int32) string {
+	return func(n int32) string {
+		if n < ready {
+			return `{"status":0,"request":"CAPCHA_NOT_READY"}`
+		}
+		return fmt.Sprintf(`{"status":1,"request":%q}`, token)
+	}
+}
+
+func TestCaptchaServiceSolveToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		keyParam   string
+		submit     string
+		poll       func(n int32) string
+		want       string
+		wantPolls  int32
+		wantErr    string
+		wantStatus int
+	}{
+		{
+			name:      "polls until the token is ready",
+			method:    "turnstile",
+			keyParam:  "sitekey",
+			submit:    submitted,
+			poll:      notReadyUntil(3, "token-1"),
+			want:      "token-1",
+			wantPolls: 3,
+		},
+		{
+			name:      "recaptcha sends a googlekey",
+			method:    "userrecaptcha",
+			keyParam:  "googlekey",
+			submit:    submitted,
+			poll:      notReadyUntil(1, "token-2"),
+			want:      "token-2",
+			wantPolls: 1,
+		},
+		{
+			name:     "submit rejected",
+			method:   "hcaptcha",
+			keyParam: "sitekey",
+			submit:   `{"status":0,"request":"ERROR_WRONG_USER_KEY"}`,
+			wantErr:  "submitting captcha failed: ERROR_WRONG_USER_KEY",
+		},
+		{
+			name:     "unsolvable",
+			method:   "turnstile",
+			keyParam: "sitekey",
+			submit:   submitted,
+			poll: func(n int32) string {
+				if n < 2 {
+					return `{"status":0,"request":"CAPCHA_NOT_READY"}`
+				}
+				return `{"status":0,"request":"ERROR_CAPTCHA_UNSOLVABLE"}`
+			},
+			wantPolls: 2,
+			wantErr:   "solving captcha failed: ERROR_CAPTCHA_UNSOLVABLE",
+		},
+		{
+			name:     "invalid response",
+			method:   "turnstile",
+			keyParam: "sitekey",
+			submit:   "OK|4242",
+			wantErr:  "error decoding /in.php response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, solver := newCaptchaService(t, tt.submit, tt.poll)
+
+			token, err := solver.solveToken(context.Background(), tt.method, "site-key", "https://example.com/login")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("solveToken() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("solveToken() error: %v", err)
+			}
+			if token != tt.want {
+				t.Errorf("solveToken() = %q, want %q", token, tt.want)
+			}
+			if got := service.polls.Load(); got != tt.wantPolls {
+				t.Errorf("polls = %d, want %d", got, tt.wantPolls)
+			}
+
+			params := <-service.params
+			want := map[string]string{"key": "secret", "method": tt.method, "pageurl": "https://example.com/login", "json": "1", tt.keyParam: "site-key"}
+			for key, value := range want {
+				if params[key] != value {
+					t.Errorf("submitted %s = %q, want %q", key, params[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestCaptchaServiceSolveTokenStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+	solver := newCaptchaServiceSolver(server.URL, "secret", time.Second)
+
+	_, err := solver.solveToken(context.Background(), "turnstile", "site-key", "https://example.com")
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("solveToken() error = %v, want a 502 HTTPStatusError", err)
+	}
+}
+
+func TestCaptchaServiceSolveTokenStops(t *testing.T) {
+	neverReady := func(n int32) string { return `{"status":0,"request":"CAPCHA_NOT_READY"}` }
+
+	t.Run("timeout", func(t *testing.T) {
+		_, solver := newCaptchaService(t, submitted, neverReady)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := solver.solveToken(ctx, "turnstile", "site-key", "https://example.com")
+		if !isTimeout(err) {
+			t.Errorf("solveToken() error = %v, want a timeout", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		service, solver := newCaptchaService(t, submitted, neverReady)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			for service.polls.Load() < 2 {
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+		}()
+
+		_, err := solver.solveToken(ctx, "turnstile", "site-key", "https://example.com")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("solveToken() error = %v, want context.Canceled", err)
+		}
+	})
+}
+
+func TestCaptchaServiceSolveErrors(t *testing.T) {
+	neverReady := func(n int32) string { return `{"status":0,"request":"CAPCHA_NOT_READY"}` }
+	turnstile := Challenge{Kind: ChallengeTurnstile, SiteKey: "site-key"}
+
+	t.Run("not configured", func(t *testing.T) {
+		solver := newCaptchaServiceSolver("", "", time.Second)
+		_, err := solver.Solve(context.Background(), nil, "https://example.com", turnstile, nil)
+		if kind := pageErrorKind(err); kind != PageErrorSolverUnavailable {
+			t.Errorf("Solve() error = %v, want %s", err, PageErrorSolverUnavailable)
+		}
+	})
+
+	t.Run("challenge without widget", func(t *testing.T) {
+		_, solver := newCaptchaService(t, submitted, neverReady)
+		_, err := solver.Solve(context.Background(), nil, "https://example.com", Challenge{Kind: ChallengeCloudflare}, nil)
+		if kind := pageErrorKind(err); kind != PageErrorSolverUnavailable {
+			t.Errorf("Solve() error = %v, want %s", err, PageErrorSolverUnavailable)
+		}
+	})
+
+	t.Run("solver timeout", func(t *testing.T) {
+		_, solver := newCaptchaService(t, submitted, neverReady)
+		solver.timeout = 50 * time.Millisecond
+		_, err := solver.Solve(context.Background(), nil, "https://example.com", turnstile, nil)
+		if kind := pageErrorKind(err); kind != PageErrorTimeout {
+			t.Errorf("Solve() error = %v, want %s", err, PageErrorTimeout)
+		}
+	})
+
+	t.Run("cancelled run", func(t *testing.T) {
+		_, solver := newCaptchaService(t, submitted, neverReady)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := solver.Solve(ctx, nil, "https://example.com", turnstile, nil)
+		if !errors.Is(err, context.DeadlineExceeded) || pageErrorKind(err) != "" {
+			t.Errorf("Solve() error = %v, want the run's context error", err)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ChallengeKind is the kind of bot challenge a page shows.
+type ChallengeKind string
+
+const (
+	// ChallengeCloudflare is Cloudflare's interstitial, which has no widget
+	// a captcha service could solve.
+	ChallengeCloudflare ChallengeKind = "cloudflare"
+	ChallengeTurnstile  ChallengeKind = "turnstile"
+	ChallengeRecaptcha  ChallengeKind = "recaptcha"
+	ChallengeHCaptcha   ChallengeKind = "hcaptcha"
+)
+
+// Challenge is a bot challenge found on a page instead of its content.
+type Challenge struct {
+	Kind    ChallengeKind `json:"kind"`
+	SiteKey string        `json:"siteKey"`
+}
+
+// ChallengeSolver gets a page past the challenge it shows.
+type ChallengeSolver interface {
+	// Solve solves the challenge page shows after it was loaded from url.
+	// The returned cookies and user agent are kept for the site and set on
+	// the page before url is loaded again. Returning none leaves the page
+	// as it is.
+	Solve(ctx context.Context, page *rod.Page, url string, challenge Challenge, proxy *models.Proxy) (UserAgentWithCookies, error)
+}
+
+// challengeSolverFor returns the solver the endpoint chose, FlareSolverr when
+// it chose none.
+func challengeSolverFor(config *models.ChallengeSolverConfig) ChallengeSolver {
+	timeout := defaultSolverTimeout
+	solverType := models.ChallengeSolverFlareSolverr
+	if config != nil {
+		if config.TimeoutSeconds > 0 {
+			timeout = time.Duration(config.TimeoutSeconds) * time.Second
+		}
+		if config.Type != "" {
+			solverType = config.Type
+		}
+	}
+
+	switch solverType {
+	case models.ChallengeSolverNone:
+		return noopSolver{}
+	case models.ChallengeSolverCaptchaService:
+		return newCaptchaServiceSolver(os.Getenv("CAPTCHA_SERVICE_URL"), os.Getenv("CAPTCHA_SERVICE_API_KEY"), timeout)
+	default:
+		return newFlareSolverrSolver(os.Getenv("FLARESOLVER_URL"), timeout)
+	}
+}
+
+const defaultSolverTimeout = 30 * time.Second
+
+// noopSolver leaves challenges unsolved, for sites the browser gets through
+// on its own.
+type noopSolver struct{}
+
+func (noopSolver) Solve(ctx context.Context, page *rod.Page, url string, challenge Challenge, proxy *models.Proxy) (UserAgentWithCookies, error) {
+	return UserAgentWithCookies{}, nil
+}
+
+// BEGIN: challenge detection
+
+// detectChallenge reports the challenge page shows, if any. Captcha widgets
+// only count while the element waited for is missing, plenty of pages show
+// one in a form next to their content.
+func detectChallenge(page *rod.Page, elementToWaitFor Selector) (Challenge, bool) {
+	res, err := page.Eval(`() => {
+		const widget = (selector, kind) => {
+			const el = document.querySelector(selector)
+			return el ? { kind, siteKey: el.getAttribute("data-sitekey") || "" } : null
+		}
+		const interstitial = document.title === "Just a moment..."
+			|| document.querySelector("#challenge-form, #challenge-running, #cf-challenge-running, script[src*='/cdn-cgi/challenge-platform/']")
+		const found = widget(".cf-turnstile[data-sitekey]", "turnstile")
+			|| widget(".g-recaptcha[data-sitekey]", "recaptcha")
+			|| widget(".h-captcha[data-sitekey]", "hcaptcha")
+		if (interstitial) {
+			return found || { kind: "cloudflare", siteKey: "" }
+		}
+		return found ? { ...found, widgetOnly: true } : null
+	}`)
+	if err != nil || res.Value.Nil() {
+		return Challenge{}, false
+	}
+
+	challenge := Challenge{
+		Kind:    ChallengeKind(res.Value.Get("kind").Str()),
+		SiteKey: res.Value.Get("siteKey").Str(),
+	}
+	if res.Value.Get("widgetOnly").Bool() && !elementToWaitFor.IsEmpty() {
+		if has, _, err := pageHas(page, elementToWaitFor); err == nil && has {
+			return Challenge{}, false
+		}
+	}
+	return challenge, true
+}
+
+// END: challenge detection
+
+// BEGIN: challenge cookies
+
+// challengeCookieKey is the cookie store key of url's site. Challenge cookies
+// are bound to the IP that solved the challenge.
+func challengeCookieKey(url string, proxy *models.Proxy) string {
+	key := helpers.GetBaseURL(url)
+	if proxy != nil {
+		key += "|" + proxy.Server()
+	}
+	return key
+}
+
+// cachedChallengeCookies returns the stored cookies that passed the site's
+// challenge before.
+func cachedChallengeCookies(ctx context.Context, key string) UserAgentWithCookies {
+	cookies, _ := getCookieSession(ctx, models.CookieSessionChallenge, key)
+	return cookies
+}
+
+// challengeCookieNames are the cookies that prove a passed challenge, the
+// stored cookies are dropped once one of them expires.
+var challengeCookieNames = []string{"cf_clearance"}
+
+func saveChallengeCookies(ctx context.Context, key string, pageURL string, cookies UserAgentWithCookies) {
+	if err := saveCookieSession(ctx, models.CookieSessionChallenge, key, pageURL, cookies, challengeCookieNames); err != nil {
+		log.Printf("Error saving challenge cookies: %v", err)
+	}
+}
+
+// applyChallengeCookies sets the cookies and the user agent that passed the
+// challenge on the page.
+func applyChallengeCookies(page *rod.Page, cookies UserAgentWithCookies) {
+	if len(cookies.Cookie) > 0 {
+		page.MustSetCookies(toCookieParams(cookies.Cookie)...)
+	}
+	if cookies.UserAgent != "" {
+		fmt.Printf("Setting User-Agent: %s\n", cookies.UserAgent)
+		page.MustSetUserAgent(&proto.NetworkSetUserAgentOverride{
+			UserAgent: cookies.UserAgent,
+		})
+	}
+}
+
+// END: challenge cookies
+
+// loadPage navigates page to url and solves the challenge shown instead of
+// it, if any.
+func loadPage(ctx context.Context, page *rod.Page, url string, elementToWaitFor Selector, opts pageOptions) error {
+	if err := navigatePage(ctx, page, url); err != nil {
+		return navigationError(url, err)
+	}
+
+	challenge, found := detectChallenge(page, elementToWaitFor)
+	if !found {
+		return nil
+	}
+	log.Printf("Found %s challenge on %s", challenge.Kind, url)
+
+	solver := opts.solver
+	if solver == nil {
+		solver = challengeSolverFor(nil)
+	}
+	cookies, err := solver.Solve(ctx, page, url, challenge, opts.proxy)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var pageErr *PageError
+		if opts.solverOptional && errors.As(err, &pageErr) && pageErr.Kind != PageErrorBlocked {
+			log.Printf("Loading %s without solving its challenge: %v", url, err)
+			return nil
+		}
+		return err
+	}
+	if len(cookies.Cookie) == 0 && cookies.UserAgent == "" {
+		log.Printf("Leaving the %s challenge on %s unsolved", challenge.Kind, url)
+		return nil
+	}
+
+	saveChallengeCookies(ctx, challengeCookieKey(url, opts.proxy), url, cookies)
+	applyChallengeCookies(page, cookies)
+	if err := navigatePage(ctx, page, url); err != nil {
+		return navigationError(url, err)
+	}
+	if challenge, found := detectChallenge(page, elementToWaitFor); found {
+		return &PageError{Kind: PageErrorBlocked, URL: url, Err: fmt.Errorf("%s challenge is still shown after solving it", challenge.Kind)}
+	}
+	return nil
+}
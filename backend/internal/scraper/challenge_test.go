@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"scrapeit/internal/models"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestChallengeSolverFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *models.ChallengeSolverConfig
+		want   ChallengeSolver
+	}{
+		{"default", nil, &flareSolverrSolver{timeout: defaultSolverTimeout}},
+		{"flaresolverr", &models.ChallengeSolverConfig{Type: models.ChallengeSolverFlareSolverr, TimeoutSeconds: 60}, &flareSolverrSolver{timeout: time.Minute}},
+		{"none", &models.ChallengeSolverConfig{Type: models.ChallengeSolverNone}, noopSolver{}},
+		{"captcha service", &models.ChallengeSolverConfig{Type: models.ChallengeSolverCaptchaService, TimeoutSeconds: 90}, &captchaServiceSolver{timeout: 90 * time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := challengeSolverFor(tt.config)
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.want) {
+				t.Fatalf("challengeSolverFor() = %T, want %T", got, tt.want)
+			}
+			switch solver := got.(type) {
+			case *flareSolverrSolver:
+				if want := tt.want.(*flareSolverrSolver).timeout; solver.timeout != want {
+					t.Errorf("timeout = %s, want %s", solver.timeout, want)
+				}
+			case *captchaServiceSolver:
+				if want := tt.want.(*captchaServiceSolver).timeout; solver.timeout != want {
+					t.Errorf("timeout = %s, want %s", solver.timeout, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNoopSolver(t *testing.T) {
+	cookies, err := noopSolver{}.Solve(context.Background(), nil, "https://example.com", Challenge{Kind: ChallengeCloudflare}, nil)
+	if err != nil {
+		t.Fatalf("Solve() error: %v", err)
+	}
+	if len(cookies.Cookie) != 0 || cookies.UserAgent != "" {
+		t.Errorf("Solve() = %+v, want nothing to apply", cookies)
+	}
+}
+
+var challengePages = map[string]string{
+	"/content":      `<html><head><title>Shop</title></head><body><div class="item">Item</div></body></html>`,
+	"/interstitial": `<html><head><title>Just a moment...</title></head><body><div id="challenge-running"></div></body></html>`,
+	"/turnstile":    `<html><head><title>Just a moment...</title></head><body><div class="cf-turnstile" data-sitekey="0xturnstile"></div></body></html>`,
+	"/recaptcha":    `<html><body><form><div class="g-recaptcha" data-sitekey="recaptcha-key"></div></form></body></html>`,
+	"/form-widget":  `<html><body><div class="item">Item</div><form><div class="h-captcha" data-sitekey="hcaptcha-key"></div></form></body></html>`,
+}
+
+func TestDetectChallenge(t *testing.T) {
+	browser := testBrowser(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := challengePages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name     string
+		path     string
+		waitFor  Selector
+		want     Challenge
+		wantSeen bool
+	}{
+		{"content", "/content", CSS(".item"), Challenge{}, false},
+		{"cloudflare interstitial", "/interstitial", CSS(".item"), Challenge{Kind: ChallengeCloudflare}, true},
+		{"turnstile on the interstitial", "/turnstile", CSS(".item"), Challenge{Kind: ChallengeTurnstile, SiteKey: "0xturnstile"}, true},
+		{"widget instead of the content", "/recaptcha", CSS(".item"), Challenge{Kind: ChallengeRecaptcha, SiteKey: "recaptcha-key"}, true},
+		{"widget next to the content", "/form-widget", CSS(".item"), Challenge{}, false},
+		{"widget next to xpath content", "/form-widget", Selector{Value: `//div[@class="item"]`, Language: models.SelectorLanguageXPath}, Challenge{}, false},
+		{"widget without a selector to wait for", "/form-widget", Selector{}, Challenge{Kind: ChallengeHCaptcha, SiteKey: "hcaptcha-key"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := browser.Page(proto.TargetCreateTarget{URL: server.URL + tt.path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer page.Close()
+			if err := page.WaitLoad(); err != nil {
+				t.Fatal(err)
+			}
+
+			got, seen := detectChallenge(page, tt.waitFor)
+			if seen != tt.wantSeen || got != tt.want {
+				t.Errorf("detectChallenge() = %+v, %v, want %+v, %v", got, seen, tt.want, tt.wantSeen)
+			}
+		})
+	}
+}
@@ -1,23 +1,22 @@
 package scraper
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	"log"
+	"scrapeit/internal/models"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type Cookie struct {
-	Domain   string `json:"domain"`
-	Expiry   int64  `json:"expiry"`
-	HttpOnly bool   `json:"httpOnly"`
-	Name     string `json:"name"`
-	Path     string `json:"path"`
-	SameSite string `json:"sameSite"`
-	Secure   bool   `json:"secure"`
-	Value    string `json:"value"`
-}
+type Cookie = models.Cookie
 
 type UserAgentWithCookies struct {
 	Cookie      []Cookie
@@ -25,56 +24,176 @@ type UserAgentWithCookies struct {
 	LastUpdated time.Time
 }
 
-type CookieStore struct {
-	mu      sync.Mutex
-	Cookies map[string]UserAgentWithCookies `json:"cookies"`
-}
+const (
+	cookieSessionsCollection = "cookie_sessions"
+	cookieTTLRulesCollection = "cookie_ttl_rules"
+)
+
+var cookieSessionIndexOnce sync.Once
 
-var cookieFile = "cookies.json"
+func cookieSessions() (*mongo.Collection, error) {
+	client, err := models.GetDbClient()
+	if err != nil {
+		return nil, err
+	}
+	collection := client.Database("scrapeit").Collection(cookieSessionsCollection)
 
-func LoadCookieStore() (*CookieStore, error) {
-	store := &CookieStore{Cookies: make(map[string]UserAgentWithCookies)}
-	if _, err := os.Stat(cookieFile); err == nil {
-		data, err := os.ReadFile(cookieFile)
+	// Mongo removes sessions once they expired, ExpiresAt is still checked
+	// as its cleanup only runs every minute.
+	cookieSessionIndexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetName("expiresAt_ttl").SetExpireAfterSeconds(0),
+		})
 		if err != nil {
-			return nil, err
-		}
-		if err := json.Unmarshal(data, store); err != nil {
-			return nil, err
+			log.Printf("Error creating cookie session TTL index: %v", err)
 		}
+	})
+	return collection, nil
+}
+
+// defaultCookieTTL is used for domains without a TTL rule. Challenge cookies
+// are fetched again after COOKIE_CHALLENGE_TTL_SECONDS, login sessions last
+// until a session cookie expires or they are found to be logged out.
+func defaultCookieTTL(kind models.CookieSessionKind) time.Duration {
+	if kind == models.CookieSessionLogin {
+		return 0
 	}
-	return store, nil
+	return time.Duration(envInt("COOKIE_CHALLENGE_TTL_SECONDS", 120)) * time.Second
 }
 
-func saveCookieStore(store *CookieStore) error {
-	store.mu.Lock()
-	defer store.mu.Unlock()
-	data, err := json.Marshal(store)
+// cookieTTL returns the TTL of the most specific rule matching domain, so a
+// rule for example.com also covers www.example.com.
+func cookieTTL(ctx context.Context, kind models.CookieSessionKind, domain string) time.Duration {
+	client, err := models.GetDbClient()
 	if err != nil {
-		return err
+		return defaultCookieTTL(kind)
+	}
+	rules := client.Database("scrapeit").Collection(cookieTTLRulesCollection)
+
+	for candidate := domain; candidate != ""; {
+		var rule models.CookieTTLRule
+		err := rules.FindOne(ctx, bson.M{"_id": candidate}).Decode(&rule)
+		if err == nil {
+			return time.Duration(rule.TTLSeconds) * time.Second
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Error loading cookie TTL of %s: %v", domain, err)
+			break
+		}
+		_, parent, found := strings.Cut(candidate, ".")
+		if !found || !strings.Contains(parent, ".") {
+			break
+		}
+		candidate = parent
+	}
+	return defaultCookieTTL(kind)
+}
+
+// cookieSessionExpiry is when the session stops being used. Only the
+// cookies named in sessionCookies end it, short lived tracking cookies are
+// set again by the site. A zero ttl leaves it to those cookies.
+func cookieSessionExpiry(updated time.Time, ttl time.Duration, cookies []Cookie, sessionCookies []string) *time.Time {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		end := updated.Add(ttl)
+		expiresAt = &end
+	}
+	for _, cookie := range cookies {
+		if cookie.Expiry <= 0 || !slices.Contains(sessionCookies, cookie.Name) {
+			continue
+		}
+		end := time.Unix(cookie.Expiry, 0)
+		if expiresAt == nil || end.Before(*expiresAt) {
+			expiresAt = &end
+		}
+	}
+	return expiresAt
+}
+
+// unexpiredCookies drops the cookies that expired before now.
+func unexpiredCookies(cookies []Cookie, now time.Time) []Cookie {
+	kept := make([]Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		if cookie.Expiry > 0 && !now.Before(time.Unix(cookie.Expiry, 0)) {
+			continue
+		}
+		kept = append(kept, cookie)
+	}
+	return kept
+}
+
+// CookieSessionValid reports whether the session may still be used.
+func CookieSessionValid(session models.CookieSession) bool {
+	if session.Kind == models.CookieSessionLogin && len(session.Cookies) == 0 {
+		return false
 	}
-	return os.WriteFile(cookieFile, data, 0644)
+	return session.ExpiresAt == nil || time.Now().Before(*session.ExpiresAt)
 }
 
-func GetValidCookies(store *CookieStore, url string) (UserAgentWithCookies, bool) {
-	store.mu.Lock()
-	defer store.mu.Unlock()
-	cookies, exists := store.Cookies[url]
-	if !exists {
+// getCookieSession returns the stored cookies for key when they are still
+// valid.
+func getCookieSession(ctx context.Context, kind models.CookieSessionKind, key string) (UserAgentWithCookies, bool) {
+	collection, err := cookieSessions()
+	if err != nil {
+		log.Printf("Error loading cookies of %s: %v", key, err)
+		return UserAgentWithCookies{}, false
+	}
+
+	var session models.CookieSession
+	if err := collection.FindOne(ctx, bson.M{"_id": key, "kind": kind}).Decode(&session); err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Error loading cookies of %s: %v", key, err)
+		}
 		return UserAgentWithCookies{}, false
 	}
-	if time.Since(cookies.LastUpdated) > 2*time.Minute {
-		fmt.Println("Cookies are expired for URL: ", url)
+	if !CookieSessionValid(session) {
+		fmt.Println("Cookies are expired for: ", key)
 		return UserAgentWithCookies{}, false
 	}
-	fmt.Println("Using cookies for URL: ", url)
-	return cookies, true
 
+	fmt.Println("Using cookies for: ", key)
+	return UserAgentWithCookies{Cookie: session.Cookies, UserAgent: session.UserAgent, LastUpdated: session.Updated}, true
+}
+
+// saveCookieSession stores the unexpired cookies for key, with the TTL of
+// the domain of pageURL. The session ends early when one of sessionCookies
+// expires.
+func saveCookieSession(ctx context.Context, kind models.CookieSessionKind, key string, pageURL string, cookies UserAgentWithCookies, sessionCookies []string) error {
+	collection, err := cookieSessions()
+	if err != nil {
+		return err
+	}
+
+	domain := pageDomain(pageURL)
+	updated := time.Now()
+	live := unexpiredCookies(cookies.Cookie, updated)
+	session := models.CookieSession{
+		Key:       key,
+		Kind:      kind,
+		Domain:    domain,
+		Cookies:   live,
+		UserAgent: cookies.UserAgent,
+		Updated:   updated,
+		ExpiresAt: cookieSessionExpiry(updated, cookieTTL(ctx, kind, domain), live, sessionCookies),
+	}
+
+	_, err = collection.ReplaceOne(ctx, bson.M{"_id": key}, session, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error saving cookies of %s: %w", key, err)
+	}
+	return nil
 }
 
-func SetCookies(store *CookieStore, url string, input UserAgentWithCookies) {
-	store.mu.Lock()
-	store.Cookies[url] = input
-	store.mu.Unlock()
-	saveCookieStore(store)
+func deleteCookieSession(ctx context.Context, key string) error {
+	collection, err := cookieSessions()
+	if err != nil {
+		return err
+	}
+	if _, err := collection.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		return fmt.Errorf("error deleting cookies of %s: %w", key, err)
+	}
+	return nil
 }
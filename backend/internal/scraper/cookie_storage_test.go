@@ -0,0 +1,60 @@
+package scraper
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCookieSessionExpiry(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		end := updated.Add(d)
+		return &end
+	}
+	cookie := func(name string, expiresIn time.Duration) Cookie {
+		return Cookie{Name: name, Expiry: updated.Add(expiresIn).Unix()}
+	}
+	session := []string{"cf_clearance"}
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		cookies []Cookie
+		want    *time.Time
+	}{
+		{"ttl without cookies", time.Hour, nil, at(time.Hour)},
+		{"no ttl and no session cookie", 0, []Cookie{cookie("__cf_bm", 30*time.Minute)}, nil},
+		{"short lived other cookies are ignored", time.Hour, []Cookie{cookie("__cf_bm", 30*time.Minute), cookie("cf_clearance", 2*time.Hour)}, at(time.Hour)},
+		{"session cookie ends the session early", time.Hour, []Cookie{cookie("cf_clearance", 10*time.Minute)}, at(10 * time.Minute)},
+		{"session cookie without ttl", 0, []Cookie{cookie("cf_clearance", 3*time.Hour), cookie("_ga", time.Minute)}, at(3 * time.Hour)},
+		{"session cookie without expiry", 0, []Cookie{{Name: "cf_clearance"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cookieSessionExpiry(updated, tt.ttl, tt.cookies, session)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("cookieSessionExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnexpiredCookies(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cookies := []Cookie{
+		{Name: "expired", Expiry: now.Add(-time.Minute).Unix()},
+		{Name: "expiring now", Expiry: now.Unix()},
+		{Name: "session"},
+		{Name: "valid", Expiry: now.Add(time.Minute).Unix()},
+	}
+
+	var names []string
+	for _, cookie := range unexpiredCookies(cookies, now) {
+		names = append(names, cookie.Name)
+	}
+	if want := []string{"session", "valid"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("unexpiredCookies() = %v, want %v", names, want)
+	}
+}
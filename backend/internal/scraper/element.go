@@ -0,0 +1,161 @@
+package scraper
+
+import (
+	"fmt"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/antchfx/htmlquery"
+	"github.com/go-rod/rod"
+	"golang.org/x/net/html"
+)
+
+// Element is the part of a DOM node the field extraction needs. It is
+// implemented for live rod elements and for parsed HTML documents so the
+// same selectors work with every fetcher.
+type Element interface {
+	Element(selector Selector) (Element, error)
+	Elements(selector Selector) ([]Element, error)
+	Text() string
+	Attribute(name string) (*string, error)
+	HTML() string
+}
+
+// BEGIN: rodElement
+
+type rodElement struct {
+	el *rod.Element
+}
+
+func newRodElement(el *rod.Element) Element {
+	return &rodElement{el: el}
+}
+
+func (e *rodElement) Element(selector Selector) (Element, error) {
+	var child *rod.Element
+	var err error
+	if selector.IsXPath() {
+		child, err = e.el.ElementX(selector.Value)
+	} else {
+		child, err = e.el.Element(selector.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newRodElement(child), nil
+}
+
+func (e *rodElement) Elements(selector Selector) ([]Element, error) {
+	var children rod.Elements
+	var err error
+	if selector.IsXPath() {
+		children, err = e.el.ElementsX(selector.Value)
+	} else {
+		children, err = e.el.Elements(selector.Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return wrapRodElements(children), nil
+}
+
+func (e *rodElement) Text() string {
+	return e.el.MustEval("() => this.textContent").String()
+}
+
+func (e *rodElement) Attribute(name string) (*string, error) {
+	return e.el.Attribute(name)
+}
+
+func (e *rodElement) HTML() string {
+	return e.el.MustHTML()
+}
+
+func wrapRodElements(elems rod.Elements) []Element {
+	wrapped := make([]Element, len(elems))
+	for i, elem := range elems {
+		wrapped[i] = newRodElement(elem)
+	}
+	return wrapped
+}
+
+// END: rodElement
+
+// BEGIN: htmlElement
+
+type htmlElement struct {
+	sel *goquery.Selection
+}
+
+func newHTMLElement(sel *goquery.Selection) Element {
+	return &htmlElement{sel: sel}
+}
+
+func (e *htmlElement) Element(selector Selector) (Element, error) {
+	elements, err := e.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("no element found for selector %s", selector)
+	}
+	return elements[0], nil
+}
+
+func (e *htmlElement) Elements(selector Selector) ([]Element, error) {
+	if selector.IsXPath() {
+		return e.elementsX(selector.Value)
+	}
+
+	found := e.sel.Find(selector.Value)
+	elements := make([]Element, 0, found.Length())
+	found.Each(func(_ int, s *goquery.Selection) {
+		elements = append(elements, newHTMLElement(s))
+	})
+	return elements, nil
+}
+
+// elementsX evaluates an XPath expression relative to the element. Matched
+// attribute and text nodes are returned as elements whose text is their
+// value.
+func (e *htmlElement) elementsX(expr string) ([]Element, error) {
+	if e.sel.Length() == 0 {
+		return []Element{}, nil
+	}
+
+	nodes, err := htmlquery.QueryAll(e.sel.Nodes[0], expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpath %s: %w", expr, err)
+	}
+
+	elements := make([]Element, len(nodes))
+	for i, node := range nodes {
+		elements[i] = newHTMLElement(nodeSelection(node))
+	}
+	return elements, nil
+}
+
+func nodeSelection(node *html.Node) *goquery.Selection {
+	return goquery.NewDocumentFromNode(node).Selection
+}
+
+func (e *htmlElement) Text() string {
+	return e.sel.Text()
+}
+
+func (e *htmlElement) Attribute(name string) (*string, error) {
+	attr, exists := e.sel.Attr(name)
+	if !exists {
+		return nil, nil
+	}
+	return &attr, nil
+}
+
+func (e *htmlElement) HTML() string {
+	html, err := goquery.OuterHtml(e.sel)
+	if err != nil {
+		return ""
+	}
+	return html
+}
+
+// END: htmlElement
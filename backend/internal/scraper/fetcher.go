@@ -0,0 +1,135 @@
+package scraper
+
+import (
+	"context"
+	"scrapeit/internal/models"
+
+	"github.com/go-rod/rod"
+)
+
+// Document is a loaded page that main elements can be queried from.
+type Document interface {
+	Element(selector Selector) (Element, error)
+	Elements(selector Selector) ([]Element, error)
+	URL() string
+	// ScrollToBottom and WaitStable give lazy content a chance to render.
+	// They are no-ops for documents that are not backed by a browser.
+	ScrollToBottom() error
+	WaitStable()
+	Close() error
+}
+
+// Fetcher loads a URL into a Document, runs the page actions on it and waits
+// for elementToWaitFor to be present before returning.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error)
+}
+
+// GetFetcher returns the fetcher configured for the endpoint. Endpoints
+// without an explicit fetcher keep using the browser.
+func GetFetcher(endpoint models.Endpoint, browser *rod.Browser) Fetcher {
+	switch endpoint.Fetcher {
+	case models.FetcherTypeHTTP:
+		warnLoginIgnored(endpoint)
+		warnProxyIgnored(endpoint)
+		return newHTTPFetcher()
+	case models.FetcherTypeJSONAPI:
+		warnLoginIgnored(endpoint)
+		warnProxyIgnored(endpoint)
+		return newJSONFetcher()
+	default:
+		return &browserFetcher{
+			browser:        browser,
+			login:          endpoint.Login,
+			proxies:        newProxyRotator(endpoint.Proxy),
+			solver:         challengeSolverFor(endpoint.ChallengeSolver),
+			solverOptional: endpoint.ChallengeSolver != nil && endpoint.ChallengeSolver.Optional,
+		}
+	}
+}
+
+// BEGIN: browserFetcher
+
+type browserFetcher struct {
+	browser        *rod.Browser
+	login          *models.LoginConfig
+	proxies        *proxyRotator
+	solver         ChallengeSolver
+	solverOptional bool
+}
+
+func (f *browserFetcher) Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	proxy, err := f.proxies.proxyFor(url)
+	if err != nil {
+		return nil, err
+	}
+	release, err := acquireLoad(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	page, err := getStealthPage(ctx, f.browser, url, elementToWaitFor, pageOptions{actions: actions, login: f.login, proxy: proxy, solver: f.solver, solverOptional: f.solverOptional})
+	if err != nil {
+		return nil, err
+	}
+	return &browserDocument{page: page}, nil
+}
+
+type browserDocument struct {
+	page *rod.Page
+}
+
+func (d *browserDocument) Element(selector Selector) (Element, error) {
+	elem, err := pageElement(d.page, selector)
+	if err != nil {
+		return nil, err
+	}
+	return newRodElement(elem), nil
+}
+
+func (d *browserDocument) Elements(selector Selector) ([]Element, error) {
+	elems, err := pageElements(d.page, selector)
+	if err != nil {
+		return nil, err
+	}
+	return wrapRodElements(elems), nil
+}
+
+func (d *browserDocument) URL() string {
+	return d.page.MustInfo().URL
+}
+
+func (d *browserDocument) ScrollToBottom() error {
+	return SlowScrollToBottom(d.page)
+}
+
+func (d *browserDocument) WaitStable() {
+	d.page.MustWaitStable()
+}
+
+func (d *browserDocument) Close() error {
+	return closePage(d.page)
+}
+
+func pageElement(page *rod.Page, selector Selector) (*rod.Element, error) {
+	if selector.IsXPath() {
+		return page.ElementX(selector.Value)
+	}
+	return page.Element(selector.Value)
+}
+
+func pageElements(page *rod.Page, selector Selector) (rod.Elements, error) {
+	if selector.IsXPath() {
+		return page.ElementsX(selector.Value)
+	}
+	return page.Elements(selector.Value)
+}
+
+func pageHas(page *rod.Page, selector Selector) (bool, *rod.Element, error) {
+	if selector.IsXPath() {
+		return page.HasX(selector.Value)
+	}
+	return page.Has(selector.Value)
+}
+
+// END: browserFetcher
@@ -0,0 +1,128 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"scrapeit/internal/models"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// flareSolverrSolver has FlareSolverr load the page in its own browser and
+// hands over the cookies and user agent it got past the challenge with.
+type flareSolverrSolver struct {
+	url     string
+	timeout time.Duration
+}
+
+func newFlareSolverrSolver(url string, timeout time.Duration) *flareSolverrSolver {
+	return &flareSolverrSolver{url: url, timeout: timeout}
+}
+
+type flareSolverrRequest struct {
+	Cmd               string            `json:"cmd"`
+	URL               string            `json:"url"`
+	MaxTimeout        int64             `json:"maxTimeout"`
+	ReturnOnlyCookies bool              `json:"returnOnlyCookies"`
+	Proxy             map[string]string `json:"proxy,omitempty"`
+}
+
+type flareSolverrResponse struct {
+	Status   string `json:"status"`
+	Message  string `json:"message"`
+	Solution struct {
+		URL       string            `json:"url"`
+		Status    int               `json:"status"`
+		Headers   map[string]string `json:"headers"`
+		Response  string            `json:"response"`
+		Cookies   []Cookie          `json:"cookies"`
+		UserAgent string            `json:"userAgent"`
+	} `json:"solution"`
+}
+
+func (s *flareSolverrSolver) Solve(ctx context.Context, page *rod.Page, url string, challenge Challenge, proxy *models.Proxy) (UserAgentWithCookies, error) {
+	if s.url == "" {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: url, Err: fmt.Errorf("FLARESOLVER_URL is not set")}
+	}
+
+	solverRequest := flareSolverrRequest{
+		Cmd:               "request.get",
+		URL:               url,
+		MaxTimeout:        s.timeout.Milliseconds(),
+		ReturnOnlyCookies: true,
+	}
+	if proxy != nil {
+		solverRequest.Proxy = flareSolverrProxy(*proxy)
+	}
+	requestBody, err := json.Marshal(solverRequest)
+	if err != nil {
+		return UserAgentWithCookies{}, err
+	}
+	if err := waitForRequest(ctx, url); err != nil {
+		return UserAgentWithCookies{}, err
+	}
+
+	// FlareSolverr gives up after maxTimeout, the request a bit later.
+	reqCtx, cancel := context.WithTimeout(ctx, s.timeout+10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, fmt.Sprintf("%s/v1", s.url), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: url, Err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return UserAgentWithCookies{}, ctx.Err()
+		}
+		if isTimeout(err) {
+			return UserAgentWithCookies{}, &PageError{Kind: PageErrorTimeout, URL: url, Err: fmt.Errorf("challenge solver: %w", err)}
+		}
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: url, Err: fmt.Errorf("error calling challenge solver: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: url, Err: fmt.Errorf("error reading challenge solver response: %w", err)}
+	}
+	var response flareSolverrResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorSolverUnavailable, URL: url, Err: fmt.Errorf("error decoding challenge solver response: %w", err)}
+	}
+
+	if response.Status == "error" {
+		kind := PageErrorSolverUnavailable
+		if strings.Contains(strings.ToLower(response.Message), "timeout") {
+			kind = PageErrorTimeout
+		}
+		return UserAgentWithCookies{}, &PageError{Kind: kind, URL: url, Err: fmt.Errorf("challenge solver: %s", response.Message)}
+	}
+
+	if proxy != nil && isBlockStatus(response.Solution.Status) {
+		banProxy(*proxy, fmt.Sprintf("status %d from %s", response.Solution.Status, url))
+		return UserAgentWithCookies{}, &PageError{Kind: PageErrorBlocked, URL: url, Err: fmt.Errorf("proxy %s blocked: %w", proxy.Server(), &HTTPStatusError{URL: url, StatusCode: response.Solution.Status})}
+	}
+
+	return UserAgentWithCookies{
+		Cookie:    response.Solution.Cookies,
+		UserAgent: response.Solution.UserAgent,
+	}, nil
+}
+
+// flareSolverrProxy is the proxy option of a FlareSolverr request.
+func flareSolverrProxy(proxy models.Proxy) map[string]string {
+	option := map[string]string{"url": proxy.Server()}
+	if proxy.Username != "" {
+		option["username"] = proxy.Username
+		option["password"] = proxy.Password
+	}
+	return option
+}
@@ -5,21 +5,22 @@ import (
 	"fmt"
 	"scrapeit/internal/helpers"
 	"scrapeit/internal/models"
-
-	"github.com/go-rod/rod"
 )
 
 // PageData stores the page and its main element
 type PageData struct {
-	Page       *rod.Page
-	Element    *rod.Element
+	Page       Document
+	Element    Element
 	ActualLink string
 }
 
-func getMainElements(page *rod.Page, endpoint models.Endpoint, scrapeType ScrapeType, limit int) ([]PageData, error) {
+func getMainElements(doc Document, fetcher Fetcher, endpoint models.Endpoint, scrapeType ScrapeType, limit int) ([]PageData, error) {
 	switch scrapeType {
-	case Previews:
-		elements := page.MustElements(endpoint.MainElementSelector)
+	case Previews, MultiLevel:
+		elements, err := doc.Elements(mainElementSelector(endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("error getting main elements: %w", err)
+		}
 		pageData := make([]PageData, len(elements))
 		for i, elem := range elements {
 			pageData[i] = PageData{Page: nil, Element: elem}
@@ -27,7 +28,7 @@ func getMainElements(page *rod.Page, endpoint models.Endpoint, scrapeType Scrape
 		return pageData, nil
 
 	case PreviewsWithDetails:
-		elems, err := page.Elements(endpoint.MainElementSelector)
+		elems, err := doc.Elements(mainElementSelector(endpoint))
 		if err != nil {
 			return nil, fmt.Errorf("error getting main elements: %w", err)
 		}
@@ -36,14 +37,14 @@ func getMainElements(page *rod.Page, endpoint models.Endpoint, scrapeType Scrape
 		var detailPages []PageData
 
 		for _, elem := range elems {
-			linkElem, err := elem.Element(endpoint.DetailedViewTriggerSelector)
+			linkElem, err := elem.Element(detailTriggerSelector(endpoint))
 			if err != nil {
 				fmt.Printf("error getting link element: %v", err)
 				continue
 			}
 
 			attr, err := linkElem.Attribute("href")
-			if err != nil {
+			if err != nil || attr == nil {
 				fmt.Printf("error getting href attribute: %v", err)
 				continue
 			}
@@ -51,27 +52,24 @@ func getMainElements(page *rod.Page, endpoint models.Endpoint, scrapeType Scrape
 			fullUrl := helpers.GetFullUrl(endpoint.URL, *attr)
 			fmt.Println("Full URL: ", fullUrl)
 
-			newPage, err := GetStealthPage(context.Background(), page.Browser(), fullUrl, endpoint.DetailedViewMainElementSelector)
+			newPage, err := fetcher.Fetch(context.Background(), fullUrl, detailMainElementSelector(endpoint), detailPageActions(endpoint))
 			if err != nil {
 				fmt.Printf("error getting detailed view page: %v", err)
-				newPage.MustClose()
 				continue
 			}
 
-			// SlowScrollToBottom(newPage)
-			newPage.MustWaitStable()
+			newPage.WaitStable()
 
 			fmt.Println("Navigated to detailed view")
-			// newPage.MustScreenshot("screenshot.png")
 
-			detailElem := newPage.MustElement(endpoint.DetailedViewMainElementSelector)
-			detailPages = append(detailPages, PageData{Page: newPage, Element: detailElem, ActualLink: fullUrl})
-			// check if not null
-			if detailElem != nil {
-				fmt.Println("Found detailed view element")
-			} else {
+			detailElem, err := newPage.Element(detailMainElementSelector(endpoint))
+			if err != nil {
 				fmt.Println("Detailed view element is null")
+				newPage.Close()
+				continue
 			}
+			fmt.Println("Found detailed view element")
+			detailPages = append(detailPages, PageData{Page: newPage, Element: detailElem, ActualLink: fullUrl})
 
 			if limit != -1 && len(detailPages) >= limit {
 				break
@@ -80,8 +78,11 @@ func getMainElements(page *rod.Page, endpoint models.Endpoint, scrapeType Scrape
 		return detailPages, nil
 
 	case PureDetails:
-		element := page.MustElement(endpoint.DetailedViewMainElementSelector)
-		return []PageData{{Page: nil, Element: element, ActualLink: page.MustInfo().URL}}, nil
+		element, err := doc.Element(detailMainElementSelector(endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("error getting detailed view element: %w", err)
+		}
+		return []PageData{{Page: nil, Element: element, ActualLink: doc.URL()}}, nil
 
 	default:
 		return nil, fmt.Errorf("unknown scrape type: %v", scrapeType)
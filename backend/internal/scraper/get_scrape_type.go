@@ -11,6 +11,7 @@ const (
 	Previews            ScrapeType = "previews"
 	PreviewsWithDetails ScrapeType = "previews_with_details"
 	PureDetails         ScrapeType = "pure_details"
+	MultiLevel          ScrapeType = "multi_level"
 )
 
 func GetScrapeType(endpoint models.Endpoint) ScrapeType {
@@ -19,6 +20,11 @@ func GetScrapeType(endpoint models.Endpoint) ScrapeType {
 	detailedViewTriggerSelector := strings.TrimSpace(endpoint.DetailedViewTriggerSelector)
 	detailedViewMainElementSelector := strings.TrimSpace(endpoint.DetailedViewMainElementSelector)
 
+	// Config 0: Navigation steps starting at the main elements (MultiLevel)
+	if len(endpoint.NavigationSteps) > 0 && listElementsSelector != "" {
+		return MultiLevel
+	}
+
 	// Config 1: Main Element Selector only (Previews)
 	if !withDetailedView && listElementsSelector != "" {
 		return Previews
@@ -0,0 +1,346 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"scrapeit/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	elementSnapshotsCollection = "element_snapshots"
+	archiveSaveTimeout         = time.Minute
+)
+
+var elementSnapshotIndexOnce sync.Once
+
+func elementSnapshots() (*mongo.Collection, error) {
+	client, err := models.GetDbClient()
+	if err != nil {
+		return nil, err
+	}
+	collection := client.Database("scrapeit").Collection(elementSnapshotsCollection)
+
+	elementSnapshotIndexOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: "groupId", Value: 1}, {Key: "endpointId", Value: 1}, {Key: "resultHash", Value: 1}},
+			Options: options.Index().SetName("result"),
+		})
+		if err != nil {
+			log.Printf("Error creating element snapshot index: %v", err)
+		}
+	})
+	return collection, nil
+}
+
+// BEGIN: elementArchive
+
+// elementArchive collects the raw HTML of the elements a run extracted its
+// results from. A nil *elementArchive ignores all snapshots, which is what
+// endpoints without ArchiveHTML use.
+type elementArchive struct {
+	mu        sync.Mutex
+	endpoint  models.Endpoint
+	group     models.ScrapeGroup
+	runId     string
+	snapshots []models.ElementSnapshot
+}
+
+func newElementArchive(ctx context.Context, endpoint models.Endpoint, group models.ScrapeGroup) *elementArchive {
+	if !endpoint.ArchiveHTML {
+		return nil
+	}
+	return &elementArchive{endpoint: endpoint, group: group, runId: runInfoFrom(ctx).runId}
+}
+
+// add keeps the HTML result was extracted from. Results without a unique
+// identifier are never stored, so neither is their HTML.
+func (a *elementArchive) add(result models.ScrapeResult, link string, elementHTML string, detailHTML string) {
+	if a == nil {
+		return
+	}
+	if uniqueIdentifier(a.group.Fields, result.Fields) == "" {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.snapshots = append(a.snapshots, models.ElementSnapshot{
+		GroupID:    a.group.ID,
+		EndpointID: a.endpoint.ID,
+		RunID:      a.runId,
+		ResultHash: result.UniqueHash,
+		Link:       link,
+		HTML:       elementHTML,
+		DetailHTML: detailHTML,
+		Created:    primitive.NewDateTimeFromTime(time.Now()),
+	})
+}
+
+// addElements keeps the HTML of the elements processElements turned into
+// results, which it returns in the same order.
+func (a *elementArchive) addElements(elements []PageData, results []models.ScrapeResult) {
+	if a == nil || len(elements) != len(results) {
+		return
+	}
+	for i, result := range results {
+		a.add(result, elements[i].ActualLink, elements[i].Element.HTML(), "")
+	}
+}
+
+// save replaces the stored snapshots of the archived results. Cancelled
+// runs keep their results, so their snapshots are saved as well.
+func (a *elementArchive) save(ctx context.Context) {
+	if a == nil || len(a.snapshots) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), archiveSaveTimeout)
+	defer cancel()
+
+	collection, err := elementSnapshots()
+	if err != nil {
+		log.Printf("Error archiving html of endpoint %s: %v", a.endpoint.ID, err)
+		return
+	}
+
+	writes := make([]mongo.WriteModel, len(a.snapshots))
+	for i, snapshot := range a.snapshots {
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"groupId": snapshot.GroupID, "endpointId": snapshot.EndpointID, "resultHash": snapshot.ResultHash}).
+			SetReplacement(snapshot).
+			SetUpsert(true)
+	}
+	if _, err := collection.BulkWrite(ctx, writes); err != nil {
+		log.Printf("Error archiving html of endpoint %s: %v", a.endpoint.ID, err)
+		return
+	}
+	fmt.Printf("Archived html of %d results for endpoint %s\n", len(a.snapshots), a.endpoint.ID)
+}
+
+// END: elementArchive
+
+// BEGIN: replay
+
+// ReplayStats counts what a replay did with the endpoint's snapshots.
+type ReplayStats struct {
+	Snapshots int `json:"snapshots"`
+	Updated   int `json:"updated"`
+	// Missing snapshots belong to results that were deleted since.
+	Missing int `json:"missing"`
+	// Conflicts are results whose new unique hash belongs to another
+	// result already. They are left unchanged.
+	Conflicts int `json:"conflicts"`
+	Failed    int `json:"failed"`
+}
+
+// ErrArchiveMultiLevel is returned when a multi level endpoint enables
+// ArchiveHTML. Its results are put together from several pages, so they
+// can not be extracted again from one archived element.
+var ErrArchiveMultiLevel = errors.New("archiving html is not supported for multi level endpoints")
+
+// CheckArchiveHTML returns ErrArchiveMultiLevel when the endpoint enables
+// ArchiveHTML but can not be archived.
+func CheckArchiveHTML(endpoint models.Endpoint) error {
+	if endpoint.ArchiveHTML && GetScrapeType(endpoint) == MultiLevel {
+		return ErrArchiveMultiLevel
+	}
+	return nil
+}
+
+// ReplayEndpoint extracts the endpoint's results again from their archived
+// HTML with its current field selectors and updates the stored results. No
+// page is loaded.
+func ReplayEndpoint(ctx context.Context, endpoint models.Endpoint, group models.ScrapeGroup, client *mongo.Client) (ReplayStats, error) {
+	var stats ReplayStats
+	if GetScrapeType(endpoint) == MultiLevel {
+		return stats, errors.New("multi level endpoints cannot be replayed")
+	}
+
+	snapshotCollection, err := elementSnapshots()
+	if err != nil {
+		return stats, err
+	}
+	cursor, err := snapshotCollection.Find(ctx, bson.M{"groupId": group.ID, "endpointId": endpoint.ID})
+	if err != nil {
+		return stats, fmt.Errorf("error loading snapshots: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	store := mongoReplayStore{
+		results:    client.Database("scrapeit").Collection("scrape_results"),
+		snapshots:  snapshotCollection,
+		groupID:    group.ID,
+		endpointID: endpoint.ID,
+	}
+	for cursor.Next(ctx) {
+		var snapshot models.ElementSnapshot
+		if err := cursor.Decode(&snapshot); err != nil {
+			return stats, fmt.Errorf("error reading snapshot: %w", err)
+		}
+		if err := replayInto(ctx, store, snapshot, endpoint, group, &stats); err != nil {
+			return stats, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return stats, fmt.Errorf("error loading snapshots: %w", err)
+	}
+
+	fmt.Printf("Replayed %d snapshots for endpoint %s, updated %d results, %d conflicts\n", stats.Snapshots, endpoint.ID, stats.Updated, stats.Conflicts)
+	return stats, nil
+}
+
+// replayStore holds the results and snapshots of the endpoint being
+// replayed.
+type replayStore interface {
+	// hashTaken reports whether a result already has the unique hash.
+	hashTaken(ctx context.Context, hash string) (bool, error)
+	// updateResult replaces the result stored under oldHash and reports
+	// whether there was one.
+	updateResult(ctx context.Context, oldHash string, result models.ScrapeResult) (bool, error)
+	updateSnapshotHash(ctx context.Context, id primitive.ObjectID, hash string) error
+}
+
+type mongoReplayStore struct {
+	results    *mongo.Collection
+	snapshots  *mongo.Collection
+	groupID    primitive.ObjectID
+	endpointID string
+}
+
+func (s mongoReplayStore) hashTaken(ctx context.Context, hash string) (bool, error) {
+	count, err := s.results.CountDocuments(ctx, bson.M{"groupId": s.groupID, "endpointId": s.endpointID, "uniqueHash": hash})
+	return count > 0, err
+}
+
+func (s mongoReplayStore) updateResult(ctx context.Context, oldHash string, result models.ScrapeResult) (bool, error) {
+	update, err := s.results.UpdateOne(ctx,
+		bson.M{"groupId": s.groupID, "endpointId": s.endpointID, "uniqueHash": oldHash},
+		bson.M{"$set": bson.M{
+			"uniqueHash":          result.UniqueHash,
+			"fields":              result.Fields,
+			"timestampLastUpdate": result.TimestampLastUpdate,
+		}})
+	if err != nil {
+		return false, err
+	}
+	return update.MatchedCount > 0, nil
+}
+
+func (s mongoReplayStore) updateSnapshotHash(ctx context.Context, id primitive.ObjectID, hash string) error {
+	_, err := s.snapshots.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"resultHash": hash}})
+	return err
+}
+
+// replayInto replays one snapshot and stores its result, counting the
+// outcome in stats. Only store errors are returned.
+func replayInto(ctx context.Context, store replayStore, snapshot models.ElementSnapshot, endpoint models.Endpoint, group models.ScrapeGroup, stats *ReplayStats) error {
+	stats.Snapshots++
+
+	result, err := replaySnapshot(snapshot, endpoint, group)
+	if err != nil {
+		log.Printf("Error replaying snapshot %s: %v", snapshot.ID.Hex(), err)
+		stats.Failed++
+		return nil
+	}
+
+	if result.UniqueHash != snapshot.ResultHash {
+		taken, err := store.hashTaken(ctx, result.UniqueHash)
+		if err != nil {
+			return fmt.Errorf("error checking unique hash: %w", err)
+		}
+		if taken {
+			log.Printf("Not replaying snapshot %s, another result already has its unique hash %s", snapshot.ID.Hex(), result.UniqueHash)
+			stats.Conflicts++
+			return nil
+		}
+	}
+
+	found, err := store.updateResult(ctx, snapshot.ResultHash, result)
+	if err != nil {
+		return fmt.Errorf("error updating result: %w", err)
+	}
+	if !found {
+		stats.Missing++
+		return nil
+	}
+	stats.Updated++
+
+	// A fixed unique identifier selector changes the hash, the snapshot
+	// follows its result.
+	if result.UniqueHash != snapshot.ResultHash {
+		if err := store.updateSnapshotHash(ctx, snapshot.ID, result.UniqueHash); err != nil {
+			log.Printf("Error updating snapshot %s: %v", snapshot.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// replaySnapshot extracts the result from the snapshot the way the run that
+// archived it did.
+func replaySnapshot(snapshot models.ElementSnapshot, endpoint models.Endpoint, group models.ScrapeGroup) (models.ScrapeResult, error) {
+	element, err := parseElementHTML(snapshot.HTML)
+	if err != nil {
+		return models.ScrapeResult{}, err
+	}
+
+	var result models.ScrapeResult
+	if GetScrapeType(endpoint) == PreviewsWithDetails {
+		detailElement, err := parseElementHTML(snapshot.DetailHTML)
+		if err != nil {
+			return models.ScrapeResult{}, fmt.Errorf("error parsing detail element: %w", err)
+		}
+		previewSelectors := scopeFieldSelectors(endpoint.DetailFieldSelectors, models.FieldScopePreview)
+		previewDetails, err := createDetails(element, previewSelectors, group.Fields, "detail", nil)
+		if err != nil {
+			return models.ScrapeResult{}, fmt.Errorf("error getting preview details: %w", err)
+		}
+		result, err = detailResult(previewDetails, detailElement, snapshot.Link, endpoint, group, nil)
+		if err != nil {
+			return models.ScrapeResult{}, err
+		}
+	} else {
+		results, err := processElements([]PageData{{Element: element, ActualLink: snapshot.Link}}, endpoint, group, nil)
+		if err != nil {
+			return models.ScrapeResult{}, err
+		}
+		result = results[0]
+	}
+
+	if uniqueIdentifier(group.Fields, result.Fields) == "" {
+		return models.ScrapeResult{}, errors.New("unique identifier is empty")
+	}
+	return result, nil
+}
+
+// parseElementHTML parses the outer HTML of an archived element. It is
+// parsed as template content, which keeps elements like table rows that are
+// dropped outside of their parent.
+func parseElementHTML(elementHTML string) (Element, error) {
+	template := &html.Node{Type: html.ElementNode, Data: "template", DataAtom: atom.Template}
+	nodes, err := html.ParseFragment(strings.NewReader(elementHTML), template)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing archived html: %w", err)
+	}
+	for _, node := range nodes {
+		if node.Type == html.ElementNode {
+			return newHTMLElement(goquery.NewDocumentFromNode(node).Selection), nil
+		}
+	}
+	return nil, errors.New("archived html has no element")
+}
+
+// END: replay
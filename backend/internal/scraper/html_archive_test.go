@@ -0,0 +1,119 @@
+package scraper
+
+import (
+	"context"
+	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeReplayStore struct {
+	results        map[string]models.ScrapeResult
+	snapshotHashes map[primitive.ObjectID]string
+}
+
+func (s *fakeReplayStore) hashTaken(_ context.Context, hash string) (bool, error) {
+	_, ok := s.results[hash]
+	return ok, nil
+}
+
+func (s *fakeReplayStore) updateResult(_ context.Context, oldHash string, result models.ScrapeResult) (bool, error) {
+	if _, ok := s.results[oldHash]; !ok {
+		return false, nil
+	}
+	delete(s.results, oldHash)
+	s.results[result.UniqueHash] = result
+	return true, nil
+}
+
+func (s *fakeReplayStore) updateSnapshotHash(_ context.Context, id primitive.ObjectID, hash string) error {
+	s.snapshotHashes[id] = hash
+	return nil
+}
+
+func TestReplayUniqueHashCollision(t *testing.T) {
+	fields := []models.Field{
+		{ID: "sku", Key: "unique_identifier", Type: models.FieldTypeText},
+		{ID: "title", Type: models.FieldTypeText},
+	}
+	group := models.ScrapeGroup{ID: primitive.NewObjectID(), Fields: fields}
+	// The unique identifier used to be read from span.sku, it now reads the
+	// model name that several items share.
+	endpoint := models.Endpoint{
+		ID:                  "endpoint",
+		URL:                 "https://shop.test/list",
+		MainElementSelector: "li",
+		DetailFieldSelectors: []models.FieldSelector{
+			{FieldID: "sku", Selector: "b.model"},
+			{FieldID: "title", Selector: "h2"},
+		},
+	}
+	hash := func(id string) string { return helpers.GenerateScrapeResultHash(endpoint.ID + id) }
+
+	snapshot := func(oldID, html string) models.ElementSnapshot {
+		return models.ElementSnapshot{ID: primitive.NewObjectID(), ResultHash: hash(oldID), HTML: html}
+	}
+	first := snapshot("a-1", `<li><h2>First</h2><b class="model">X1</b></li>`)
+	second := snapshot("a-2", `<li><h2>Second</h2><b class="model">X1</b></li>`)
+	taken := snapshot("a-3", `<li><h2>Third</h2><b class="model">Y1</b></li>`)
+	deleted := snapshot("a-4", `<li><h2>Fourth</h2><b class="model">Z1</b></li>`)
+	broken := snapshot("a-5", `<li><h2>Fifth</h2></li>`)
+
+	store := &fakeReplayStore{
+		results: map[string]models.ScrapeResult{
+			hash("a-1"): {UniqueHash: hash("a-1")},
+			hash("a-2"): {UniqueHash: hash("a-2")},
+			hash("a-3"): {UniqueHash: hash("a-3")},
+			hash("a-5"): {UniqueHash: hash("a-5")},
+			// A result that was scraped after the selector fix.
+			hash("Y1"): {UniqueHash: hash("Y1")},
+		},
+		snapshotHashes: map[primitive.ObjectID]string{},
+	}
+
+	var stats ReplayStats
+	for _, s := range []models.ElementSnapshot{first, second, taken, deleted, broken} {
+		if err := replayInto(context.Background(), store, s, endpoint, group, &stats); err != nil {
+			t.Fatalf("replayInto() error: %v", err)
+		}
+	}
+
+	want := ReplayStats{Snapshots: 5, Updated: 1, Missing: 1, Conflicts: 2, Failed: 1}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+
+	// The first snapshot takes the new hash, the second one collides with it.
+	if got := titleOf(fields, store.results[hash("X1")]); got != "First" {
+		t.Errorf("result under the new hash has title %q, want First", got)
+	}
+	for _, id := range []string{"a-2", "a-3", "a-5", "Y1"} {
+		if _, ok := store.results[hash(id)]; !ok {
+			t.Errorf("result %s was changed, want it left alone", id)
+		}
+	}
+	if _, ok := store.results[hash("a-1")]; ok {
+		t.Error("result under the old hash of the first snapshot is still stored")
+	}
+
+	if got := store.snapshotHashes[first.ID]; got != hash("X1") {
+		t.Errorf("first snapshot hash = %q, want the new hash", got)
+	}
+	for name, s := range map[string]models.ElementSnapshot{"second": second, "taken": taken, "deleted": deleted, "broken": broken} {
+		if got, ok := store.snapshotHashes[s.ID]; ok {
+			t.Errorf("%s snapshot hash updated to %q, want it unchanged", name, got)
+		}
+	}
+}
+
+func titleOf(fields []models.Field, result models.ScrapeResult) string {
+	for _, detail := range result.Fields {
+		if detail.FieldID == "title" {
+			value, _ := detail.Value.(string)
+			return value
+		}
+	}
+	return ""
+}
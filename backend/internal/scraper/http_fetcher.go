@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"scrapeit/internal/models"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const httpFetcherUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+
+// httpFetcher loads pages with a plain HTTP request and parses the returned
+// HTML without a browser. It is meant for static, server rendered pages.
+type httpFetcher struct {
+	client *http.Client
+}
+
+func newHTTPFetcher() *httpFetcher {
+	return &httpFetcher{client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (f *httpFetcher) Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	warnActionsIgnored(actions, "http")
+	resp, err := httpGet(ctx, f.client, url, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing html from %s: %w", url, err)
+	}
+
+	htmlDoc := &htmlDocument{doc: doc, url: resp.Request.URL.String()}
+	if !elementToWaitFor.IsEmpty() {
+		if _, err := htmlDoc.Element(elementToWaitFor); err != nil {
+			return nil, fmt.Errorf("%w: %s on %s: %w", ErrElementNotFound, elementToWaitFor, url, err)
+		}
+	}
+
+	return htmlDoc, nil
+}
+
+// httpGet requests url and returns the response when it has a 2xx status.
+// The caller has to close the response body.
+func httpGet(ctx context.Context, client *http.Client, url string, accept string) (*http.Response, error) {
+	release, err := acquireLoad(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if err := waitForRequest(ctx, url); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", httpFetcherUserAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting %s: %w", url, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, &HTTPStatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+
+	return resp, nil
+}
+
+type htmlDocument struct {
+	doc *goquery.Document
+	url string
+}
+
+func (d *htmlDocument) Element(selector Selector) (Element, error) {
+	return newHTMLElement(d.doc.Selection).Element(selector)
+}
+
+func (d *htmlDocument) Elements(selector Selector) ([]Element, error) {
+	return newHTMLElement(d.doc.Selection).Elements(selector)
+}
+
+func (d *htmlDocument) URL() string {
+	return d.url
+}
+
+func (d *htmlDocument) ScrollToBottom() error {
+	return nil
+}
+
+func (d *htmlDocument) WaitStable() {}
+
+func (d *htmlDocument) Close() error {
+	return nil
+}
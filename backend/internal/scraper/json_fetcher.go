@@ -0,0 +1,203 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"scrapeit/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// jsonFetcher loads endpoints whose URL returns JSON. Selectors are JSONPath
+// expressions, main element selectors point to the item array and field
+// selectors are evaluated relative to a single item. The selector language is
+// ignored.
+type jsonFetcher struct {
+	client *http.Client
+}
+
+func newJSONFetcher() *jsonFetcher {
+	return &jsonFetcher{client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (f *jsonFetcher) Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	warnActionsIgnored(actions, "json api")
+	resp, err := httpGet(ctx, f.client, url, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var value interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&value); err != nil {
+		return nil, fmt.Errorf("error decoding json from %s: %w", url, err)
+	}
+
+	doc := &jsonDocument{root: &jsonElement{value: value}, url: resp.Request.URL.String()}
+	if !elementToWaitFor.IsEmpty() {
+		if _, err := doc.Element(elementToWaitFor); err != nil {
+			return nil, fmt.Errorf("%w: path %s on %s: %w", ErrElementNotFound, elementToWaitFor.Value, url, err)
+		}
+	}
+
+	return doc, nil
+}
+
+type jsonDocument struct {
+	root *jsonElement
+	url  string
+}
+
+func (d *jsonDocument) Element(selector Selector) (Element, error) {
+	return d.root.Element(selector)
+}
+
+func (d *jsonDocument) Elements(selector Selector) ([]Element, error) {
+	return d.root.Elements(selector)
+}
+
+func (d *jsonDocument) URL() string {
+	return d.url
+}
+
+func (d *jsonDocument) ScrollToBottom() error {
+	return nil
+}
+
+func (d *jsonDocument) WaitStable() {}
+
+func (d *jsonDocument) Close() error {
+	return nil
+}
+
+// jsonElement is a value inside a decoded JSON document. Attributes are the
+// keys of an object, so AttributeToGet can pick a property of the matched
+// value.
+type jsonElement struct {
+	value interface{}
+}
+
+func (e *jsonElement) Element(selector Selector) (Element, error) {
+	value, err := evalJSONPath(selector.Value, e.value)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("no value found for path %s", selector.Value)
+	}
+	return &jsonElement{value: value}, nil
+}
+
+func (e *jsonElement) Elements(selector Selector) ([]Element, error) {
+	value, err := evalJSONPath(selector.Value, e.value)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		if value == nil {
+			return []Element{}, nil
+		}
+		items = []interface{}{value}
+	}
+
+	elements := make([]Element, len(items))
+	for i, item := range items {
+		elements[i] = &jsonElement{value: item}
+	}
+	return elements, nil
+}
+
+func (e *jsonElement) Text() string {
+	return jsonValueToString(e.value)
+}
+
+func (e *jsonElement) Attribute(name string) (*string, error) {
+	object, ok := e.value.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	value, exists := object[name]
+	if !exists {
+		return nil, nil
+	}
+	text := jsonValueToString(value)
+	return &text, nil
+}
+
+// numberElement is an element that can hold a typed number.
+type numberElement interface {
+	number(attribute string) (float64, bool)
+}
+
+// number returns the JSON number the element, or the property attribute of
+// it, holds. Number fields use it as is, parsed as price text 4.125 would
+// read as 4125.
+func (e *jsonElement) number(attribute string) (float64, bool) {
+	value := e.value
+	if attribute != "" {
+		if object, ok := value.(map[string]interface{}); ok {
+			if property, exists := object[attribute]; exists {
+				value = property
+			}
+		}
+	}
+	number, ok := value.(float64)
+	return number, ok
+}
+
+func (e *jsonElement) HTML() string {
+	raw, err := json.MarshalIndent(e.value, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// evalJSONPath evaluates path against value. Paths may be written relative
+// to the value ("@.price", ".price", "price") or absolute ("$.price").
+func evalJSONPath(path string, value interface{}) (interface{}, error) {
+	path = strings.TrimSpace(path)
+	switch {
+	case path == "":
+		return value, nil
+	case strings.HasPrefix(path, "$"):
+	case strings.HasPrefix(path, "@"):
+		path = "$" + path[1:]
+	case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+		path = "$" + path
+	default:
+		path = "$." + path
+	}
+
+	return jsonpath.Get(path, value)
+}
+
+func jsonValueToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(raw)
+	}
+}
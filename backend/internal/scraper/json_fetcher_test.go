@@ -0,0 +1,48 @@
+package scraper
+
+import (
+	"encoding/json"
+	"scrapeit/internal/models"
+	"testing"
+)
+
+func TestJSONNumberFields(t *testing.T) {
+	var item interface{}
+	if err := json.Unmarshal([]byte(`{
+		"price": 4.125,
+		"lat": 52.520008,
+		"count": 1200,
+		"label": "1.234,50 €",
+		"offer": {"amount": 19.999}
+	}`), &item); err != nil {
+		t.Fatal(err)
+	}
+	element := &jsonElement{value: item}
+	fields := []models.Field{{ID: "f", Type: models.FieldTypeNumber}}
+
+	tests := []struct {
+		name     string
+		selector models.FieldSelector
+		want     float64
+	}{
+		{"three decimals", models.FieldSelector{Selector: "price"}, 4.125},
+		{"many decimals", models.FieldSelector{Selector: "lat"}, 52.520008},
+		{"integer", models.FieldSelector{Selector: "count"}, 1200},
+		{"property of an object", models.FieldSelector{Selector: "offer", AttributeToGet: "amount"}, 19.999},
+		{"text is parsed as a price", models.FieldSelector{Selector: "label"}, 1234.5},
+		{"regex works on the text", models.FieldSelector{Selector: "count", Regex: `(\d{2})`}, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.selector.FieldID = "f"
+			details, err := getElementDetails(element, []models.FieldSelector{tt.selector}, fields, nil)
+			if err != nil {
+				t.Fatalf("getElementDetails() error: %v", err)
+			}
+			if got := details[0].Value; got != tt.want {
+				t.Errorf("value = %#v, want %v", got, tt.want)
+			}
+		})
+	}
+}
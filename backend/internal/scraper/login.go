@@ -0,0 +1,166 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	loggedInCheckTimeout = 5 * time.Second
+	loginTimeout         = 30 * time.Second
+)
+
+// sessionLocks serializes logins per session key, so parallel pages wait for
+// a running login instead of signing in again.
+var sessionLocks sync.Map
+
+func sessionLock(key string) *sync.Mutex {
+	lock, _ := sessionLocks.LoadOrStore(key, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+func sessionKey(login models.LoginConfig) string {
+	if login.SessionKey != "" {
+		return login.SessionKey
+	}
+	return helpers.GetBaseURL(login.URL) + "#" + login.Username
+}
+
+// ensureSession sets the cookies of the login session on page, signing in
+// first when there is no valid stored session. With forceLogin the stored
+// session is discarded.
+func ensureSession(ctx context.Context, page *rod.Page, login models.LoginConfig, forceLogin bool) error {
+	key := sessionKey(login)
+	lock := sessionLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if forceLogin {
+		fmt.Println("Session lost, logging in again: ", key)
+		if err := deleteCookieSession(ctx, key); err != nil {
+			log.Printf("Error discarding login session: %v", err)
+		}
+	} else if session, valid := getCookieSession(ctx, models.CookieSessionLogin, key); valid {
+		fmt.Println("Using login session: ", key)
+		return page.SetCookies(toCookieParams(session.Cookie))
+	}
+
+	cookies, err := performLogin(ctx, page, login)
+	if err != nil {
+		return fmt.Errorf("error logging in to %s: %w", login.URL, err)
+	}
+	if err := saveCookieSession(ctx, models.CookieSessionLogin, key, login.URL, UserAgentWithCookies{Cookie: cookies}, login.SessionCookieNames); err != nil {
+		log.Printf("Error saving login session: %v", err)
+	}
+	return nil
+}
+
+// performLogin runs the login steps on page and returns the cookies of the
+// new session. The cookies stay set on page.
+func performLogin(ctx context.Context, page *rod.Page, config models.LoginConfig) ([]Cookie, error) {
+	fmt.Println("Logging in at: ", config.URL)
+	if err := navigatePage(ctx, page, config.URL); err != nil {
+		return nil, err
+	}
+
+	if err := runPageActions(ctx, page, loginSteps(config)); err != nil {
+		return nil, err
+	}
+
+	if config.LoggedInSelector != "" {
+		loginCtx, cancel := context.WithTimeout(ctx, loginTimeout)
+		defer cancel()
+		if _, err := pageElement(page.Context(loginCtx), loggedInSelector(config)); err != nil {
+			return nil, fmt.Errorf("logged in selector %s not found after login: %w", loggedInSelector(config), err)
+		}
+	} else {
+		page.WaitStable(time.Second)
+	}
+
+	networkCookies, err := page.Cookies(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading session cookies: %w", err)
+	}
+	return fromNetworkCookies(networkCookies), nil
+}
+
+// loginSteps returns the login steps with the credentials filled in.
+func loginSteps(config models.LoginConfig) []models.PageAction {
+	replacer := strings.NewReplacer("{{username}}", config.Username, "{{password}}", config.Password)
+	steps := make([]models.PageAction, len(config.Steps))
+	for i, step := range config.Steps {
+		step.Value = replacer.Replace(step.Value)
+		steps[i] = step
+	}
+	return steps
+}
+
+// isLoggedIn reports whether the logged in selector is on page. Without a
+// selector the session is assumed to be alive.
+func isLoggedIn(ctx context.Context, page *rod.Page, config models.LoginConfig) bool {
+	if config.LoggedInSelector == "" {
+		return true
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, loggedInCheckTimeout)
+	defer cancel()
+	_, err := pageElement(page.Context(checkCtx), loggedInSelector(config))
+	return err == nil
+}
+
+func loggedInSelector(config models.LoginConfig) Selector {
+	return Selector{Value: config.LoggedInSelector, Language: config.LoggedInSelectorLanguage}
+}
+
+func toCookieParams(cookies []Cookie) []*proto.NetworkCookieParam {
+	params := make([]*proto.NetworkCookieParam, len(cookies))
+	for idx, c := range cookies {
+		params[idx] = &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HttpOnly,
+			SameSite: proto.NetworkCookieSameSite(c.SameSite),
+		}
+		// Session cookies have no expiry, an expiry in the past would drop
+		// them right away.
+		if c.Expiry > 0 {
+			params[idx].Expires = proto.TimeSinceEpoch(float64(c.Expiry))
+		}
+	}
+	return params
+}
+
+func fromNetworkCookies(networkCookies []*proto.NetworkCookie) []Cookie {
+	cookies := make([]Cookie, len(networkCookies))
+	for idx, c := range networkCookies {
+		cookies[idx] = Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expiry:   int64(c.Expires),
+			Secure:   c.Secure,
+			HttpOnly: c.HTTPOnly,
+			SameSite: string(c.SameSite),
+		}
+	}
+	return cookies
+}
+
+// warnLoginIgnored logs that a fetcher without a browser cannot log in.
+func warnLoginIgnored(endpoint models.Endpoint) {
+	if endpoint.Login != nil {
+		log.Printf("Ignoring login of endpoint %s, the %s fetcher does not run a browser", endpoint.ID, endpoint.Fetcher)
+	}
+}
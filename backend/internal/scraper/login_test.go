@@ -0,0 +1,75 @@
+package scraper
+
+import (
+	"reflect"
+	"scrapeit/internal/models"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestLoginSteps(t *testing.T) {
+	config := models.LoginConfig{
+		Username: "jane@example.com",
+		Password: "p{{a}}ss",
+		Steps: []models.PageAction{
+			{Type: models.PageActionTypeType, Selector: "#user", Value: "{{username}}"},
+			{Type: models.PageActionTypeType, Selector: "#pass", Value: "{{password}}"},
+			{Type: models.PageActionTypeClick, Selector: "button"},
+		},
+	}
+
+	steps := loginSteps(config)
+	got := []string{steps[0].Value, steps[1].Value, steps[2].Value}
+	if want := []string{"jane@example.com", "p{{a}}ss", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("step values = %q, want %q", got, want)
+	}
+	if config.Steps[0].Value != "{{username}}" {
+		t.Error("loginSteps() changed the config's steps")
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		config models.LoginConfig
+		want   string
+	}{
+		{"per account and site", models.LoginConfig{URL: "https://shop.test/login?next=/", Username: "jane"}, "https://shop.test#jane"},
+		{"shared key", models.LoginConfig{URL: "https://shop.test/login", Username: "jane", SessionKey: "team"}, "team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sessionKey(tt.config); got != tt.want {
+				t.Errorf("sessionKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionCookieConversion(t *testing.T) {
+	cookies := []Cookie{
+		{Name: "sid", Value: "abc", Domain: "shop.test", Path: "/", Expiry: 1900000000, Secure: true, HttpOnly: true, SameSite: "Lax"},
+		{Name: "tmp", Value: "1", Domain: "shop.test", Path: "/"},
+	}
+
+	params := toCookieParams(cookies)
+	if params[0].Expires != proto.TimeSinceEpoch(1900000000) {
+		t.Errorf("expires = %v, want 1900000000", params[0].Expires)
+	}
+	if params[1].Expires != 0 {
+		t.Errorf("session cookie expires = %v, want none", params[1].Expires)
+	}
+
+	network := make([]*proto.NetworkCookie, len(params))
+	for i, param := range params {
+		network[i] = &proto.NetworkCookie{
+			Name: param.Name, Value: param.Value, Domain: param.Domain, Path: param.Path,
+			Expires: param.Expires, Secure: param.Secure, HTTPOnly: param.HTTPOnly, SameSite: param.SameSite,
+		}
+	}
+	if got := fromNetworkCookies(network); !reflect.DeepEqual(got, cookies) {
+		t.Errorf("fromNetworkCookies() = %+v, want %+v", got, cookies)
+	}
+}
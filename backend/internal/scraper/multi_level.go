@@ -0,0 +1,261 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"scrapeit/internal/models"
+	"sync"
+	"time"
+)
+
+// BEGIN: multi level crawling
+
+// crawlBranch is an element whose fields were extracted and whose next level
+// is still to be crawled. details holds the fields of all levels so far.
+type crawlBranch struct {
+	details []interface{}
+	nextURL string
+}
+
+// multiLevelCrawler follows an endpoint's navigation steps and merges the
+// fields of every level into one result. Details are created through
+// createDetails, so detailType selects between real and test runs.
+type multiLevelCrawler struct {
+	endpoint   models.Endpoint
+	group      models.ScrapeGroup
+	fetcher    Fetcher
+	stats      *runStats
+	detailType string
+}
+
+// levelFieldSelectors returns the field selectors extracted at level.
+func levelFieldSelectors(endpoint models.Endpoint, level int) []models.FieldSelector {
+	selectors := []models.FieldSelector{}
+	for _, selector := range endpoint.DetailFieldSelectors {
+		if selector.Level == level {
+			selectors = append(selectors, selector)
+		}
+	}
+	return selectors
+}
+
+// branches extracts the level 0 fields of the listing's main elements and
+// resolves their links to the first navigation step.
+func (c *multiLevelCrawler) branches(elements []Element) []crawlBranch {
+	branches := make([]crawlBranch, 0, len(elements))
+	for _, element := range elements {
+		branch, err := c.branch(element, 0, nil, c.endpoint.URL)
+		if err != nil {
+			log.Printf("Error on level 0 of %s: %v", c.endpoint.URL, err)
+			continue
+		}
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// branch extracts the fields of level from element and resolves the link to
+// the next level. pageURL is the URL of the page element was found on.
+func (c *multiLevelCrawler) branch(element Element, level int, parentDetails []interface{}, pageURL string) (crawlBranch, error) {
+	details, err := createDetails(element, levelFieldSelectors(c.endpoint, level), c.group.Fields, c.detailType, c.stats)
+	if err != nil {
+		return crawlBranch{}, fmt.Errorf("error getting element details: %w", err)
+	}
+	if level > 0 {
+		setLinkDetail(details, findLinkFieldId(c.group.Fields), pageURL)
+	}
+
+	branch := crawlBranch{details: append(append([]interface{}{}, parentDetails...), details...)}
+	if level < len(c.endpoint.NavigationSteps) {
+		step := c.endpoint.NavigationSteps[level]
+		link, err := getTriggerLink(element, navigationTriggerSelector(step), pageURL)
+		if err != nil {
+			return crawlBranch{}, err
+		}
+		branch.nextURL = link
+	}
+	return branch, nil
+}
+
+// crawl follows branch from level down to the last level and returns the
+// merged details of every element found there. limit caps the elements taken
+// from pages of steps with Multiple set, 0 means no limit.
+func (c *multiLevelCrawler) crawl(ctx context.Context, branch crawlBranch, level int, limit int) [][]interface{} {
+	if level == len(c.endpoint.NavigationSteps) {
+		return [][]interface{}{branch.details}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	step := c.endpoint.NavigationSteps[level]
+	doc, err := c.fetcher.Fetch(ctx, branch.nextURL, navigationMainElementSelector(step), detailPageActions(c.endpoint))
+	if err != nil {
+		log.Printf("Error getting level %d page %s: %v", level+1, branch.nextURL, err)
+		return nil
+	}
+	doc.WaitStable()
+
+	var elements []Element
+	if step.Multiple {
+		elements, err = doc.Elements(navigationMainElementSelector(step))
+	} else {
+		var element Element
+		element, err = doc.Element(navigationMainElementSelector(step))
+		elements = []Element{element}
+	}
+	if err != nil {
+		log.Printf("Level %d element not found on %s: %v", level+1, branch.nextURL, err)
+		doc.Close()
+		return nil
+	}
+
+	nextBranches := make([]crawlBranch, 0, len(elements))
+	for _, element := range elements {
+		if limit > 0 && len(nextBranches) >= limit {
+			break
+		}
+		next, err := c.branch(element, level+1, branch.details, branch.nextURL)
+		if err != nil {
+			log.Printf("Error on level %d of %s: %v", level+1, branch.nextURL, err)
+			continue
+		}
+		nextBranches = append(nextBranches, next)
+	}
+	// The page is closed before going deeper, so a branch holds one page at
+	// a time.
+	doc.Close()
+
+	var leaves [][]interface{}
+	for _, next := range nextBranches {
+		leaves = append(leaves, c.crawl(ctx, next, level+1, limit)...)
+	}
+	return leaves
+}
+
+// crawlAll crawls detailConcurrency branches at a time and returns the
+// details of all leaves.
+func (c *multiLevelCrawler) crawlAll(ctx context.Context, branches []crawlBranch, limit int) [][]interface{} {
+	var leaves [][]interface{}
+	var mu sync.Mutex
+	sem := make(chan struct{}, detailConcurrency())
+	wg := sync.WaitGroup{}
+
+	for _, branch := range branches {
+		wg.Add(1)
+		go func(branch crawlBranch) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			branchLeaves := c.crawl(ctx, branch, 0, limit)
+			mu.Lock()
+			leaves = append(leaves, branchLeaves...)
+			mu.Unlock()
+		}(branch)
+	}
+
+	wg.Wait()
+	return leaves
+}
+
+// setLinkDetail sets the link field among details to url.
+func setLinkDetail(details []interface{}, linkFieldId string, url string) {
+	for i, detail := range details {
+		switch d := detail.(type) {
+		case models.ScrapeResultDetail:
+			if d.FieldID == linkFieldId {
+				d.Value = url
+				details[i] = d
+			}
+		case models.ScrapeResultDetailTest:
+			if d.FieldID == linkFieldId {
+				d.Value = url
+				details[i] = d
+			}
+		}
+	}
+}
+
+func scrapeMultiLevel(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher, stats *runStats) []models.ScrapeResult {
+	crawler := &multiLevelCrawler{
+		endpoint:   endpointToScrape,
+		group:      relevantGroup,
+		fetcher:    fetcher,
+		stats:      stats,
+		detailType: "detail",
+	}
+	results := []models.ScrapeResult{}
+	seenLinks := map[string]bool{}
+
+	pagesVisited, err := visitListingPages(ctx, fetcher, endpointToScrape, stats, func(doc Document, elems []Element) error {
+		branches := crawler.branches(elems)
+
+		links := make([]string, len(branches))
+		for i, branch := range branches {
+			links[i] = branch.nextURL
+		}
+		if endpointToScrape.PaginationConfig.OpenEnded && allSeen(seenLinks, links) {
+			fmt.Println("Page only contains links seen in this run, stopping pagination")
+			return errStopPagination
+		}
+
+		for _, leaf := range crawler.crawlAll(ctx, branches, 0) {
+			results = append(results, newScrapeResult(toScrapeResultDetails(leaf), endpointToScrape, relevantGroup))
+		}
+		return nil
+	})
+	stats.setPagesVisited(pagesVisited)
+	if err != nil {
+		log.Printf("Error paginating %s: %v", endpointToScrape.URL, err)
+	}
+
+	return results
+}
+
+func scrapeTestMultiLevel(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher) ([]models.ScrapeResultTest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	doc, err := fetcher.Fetch(ctx, endpointToScrape.URL, mainElementSelector(endpointToScrape), mainPageActions(endpointToScrape))
+	if err != nil {
+		return nil, fmt.Errorf("error getting page: %w", err)
+	}
+
+	doc.ScrollToBottom()
+	doc.WaitStable()
+
+	elems, err := doc.Elements(mainElementSelector(endpointToScrape))
+	if err != nil {
+		doc.Close()
+		return nil, fmt.Errorf("error getting main elements: %w", err)
+	}
+	if len(elems) > 5 {
+		elems = elems[:5] // Limit to 5 elements for testing
+	}
+
+	crawler := &multiLevelCrawler{
+		endpoint:   endpointToScrape,
+		group:      relevantGroup,
+		fetcher:    fetcher,
+		detailType: "test",
+	}
+	branches := crawler.branches(elems)
+	doc.Close()
+
+	results := []models.ScrapeResultTest{}
+	for _, leaf := range crawler.crawlAll(ctx, branches, 2) {
+		if result, ok := newScrapeResultTest(toScrapeResultDetailsTest(leaf), endpointToScrape, relevantGroup); ok {
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// END: multi level crawling
@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"scrapeit/internal/models"
+	"sort"
+	"testing"
+)
+
+// categoryServer serves a listing of categories, category pages listing
+// products and product pages.
+func categoryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	pages := map[string]string{
+		"/":          `<ul><li class="cat"><a href="/cat/a">Shoes</a></li><li class="cat"><a href="/cat/b">Hats</a></li></ul>`,
+		"/cat/a":     `<div class="product"><a href="/product/1">Sneaker</a></div><div class="product"><a href="/product/2">Boot</a></div>`,
+		"/cat/b":     `<div class="product"><a href="/product/3">Cap</a></div>`,
+		"/product/1": `<div class="detail"><span class="price">10</span></div>`,
+		"/product/2": `<div class="detail"><span class="price">20</span></div>`,
+		"/product/3": `<div class="detail"><span class="price">30</span></div>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<html><body>%s</body></html>", page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMultiLevelCrawl(t *testing.T) {
+	server := categoryServer(t)
+	fields := []models.Field{
+		{ID: "category", Type: models.FieldTypeText},
+		{ID: "product", Type: models.FieldTypeText},
+		{ID: "price", Type: models.FieldTypeNumber},
+		{ID: "link", Name: "Link", Type: models.FieldTypeLink},
+	}
+	endpoint := models.Endpoint{
+		URL:                 server.URL + "/",
+		Fetcher:             models.FetcherTypeHTTP,
+		MainElementSelector: "li.cat",
+		NavigationSteps: []models.NavigationStep{
+			{TriggerSelector: "a", MainElementSelector: "div.product", Multiple: true},
+			{TriggerSelector: "a", MainElementSelector: "div.detail"},
+		},
+		DetailFieldSelectors: []models.FieldSelector{
+			{FieldID: "category", Selector: "a"},
+			{FieldID: "product", Selector: "a", Level: 1},
+			{FieldID: "price", Selector: "span.price", Level: 2},
+			{FieldID: "link", Selector: "a.none", Level: 2},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  [][]interface{}
+	}{
+		{
+			name:  "every branch",
+			limit: 0,
+			want: [][]interface{}{
+				{"Hats", "Cap", 30.0, server.URL + "/product/3"},
+				{"Shoes", "Boot", 20.0, server.URL + "/product/2"},
+				{"Shoes", "Sneaker", 10.0, server.URL + "/product/1"},
+			},
+		},
+		{
+			name:  "limited elements per page",
+			limit: 1,
+			want: [][]interface{}{
+				{"Hats", "Cap", 30.0, server.URL + "/product/3"},
+				{"Shoes", "Sneaker", 10.0, server.URL + "/product/1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fetcher := newHTTPFetcher()
+			doc, err := fetcher.Fetch(ctx, endpoint.URL, mainElementSelector(endpoint), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer doc.Close()
+			elems, err := doc.Elements(mainElementSelector(endpoint))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			crawler := &multiLevelCrawler{endpoint: endpoint, group: models.ScrapeGroup{Fields: fields}, fetcher: fetcher, detailType: "detail"}
+			var got [][]interface{}
+			for _, leaf := range crawler.crawlAll(ctx, crawler.branches(elems), tt.limit) {
+				values := []interface{}{}
+				for _, detail := range toScrapeResultDetails(leaf) {
+					values = append(values, detail.Value)
+				}
+				got = append(got, values)
+			}
+			sort.Slice(got, func(i, j int) bool { return fmt.Sprint(got[i]) < fmt.Sprint(got[j]) })
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("leaves = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
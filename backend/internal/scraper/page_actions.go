@@ -0,0 +1,214 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"scrapeit/internal/models"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const defaultPageActionTimeout = 10 * time.Second
+
+// PageActionError is returned when a required page action fails. Index is
+// the position of the action in the endpoint's action list.
+type PageActionError struct {
+	Index  int
+	Action models.PageAction
+	Err    error
+}
+
+func (e *PageActionError) Error() string {
+	if e.Action.Selector != "" {
+		return fmt.Sprintf("page action %d (%s %q) failed: %v", e.Index+1, e.Action.Type, e.Action.Selector, e.Err)
+	}
+	return fmt.Sprintf("page action %d (%s) failed: %v", e.Index+1, e.Action.Type, e.Err)
+}
+
+func (e *PageActionError) Unwrap() error {
+	return e.Err
+}
+
+// mainPageActions returns the actions run on the pages loaded from the
+// endpoint's URL.
+func mainPageActions(endpoint models.Endpoint) []models.PageAction {
+	return scopePageActions(endpoint.Actions, models.PageActionScopeMain)
+}
+
+// detailPageActions returns the actions run on detail and navigation pages.
+func detailPageActions(endpoint models.Endpoint) []models.PageAction {
+	return scopePageActions(endpoint.Actions, models.PageActionScopeDetail)
+}
+
+func scopePageActions(actions []models.PageAction, scope models.PageActionScope) []models.PageAction {
+	scoped := []models.PageAction{}
+	for _, action := range actions {
+		if action.Scope == "" || action.Scope == scope {
+			scoped = append(scoped, action)
+		}
+	}
+	return scoped
+}
+
+// warnActionsIgnored logs that a fetcher without a browser skips the actions.
+func warnActionsIgnored(actions []models.PageAction, fetcherName string) {
+	if len(actions) > 0 {
+		log.Printf("Ignoring %d page actions, the %s fetcher does not run a browser", len(actions), fetcherName)
+	}
+}
+
+// runPageActions runs the actions on page in order. Failing optional actions
+// are logged and skipped, the first failing required action stops the run.
+func runPageActions(ctx context.Context, page *rod.Page, actions []models.PageAction) error {
+	for i, action := range actions {
+		err := runPageAction(ctx, page, action)
+		if err == nil {
+			continue
+		}
+		if action.Optional {
+			log.Printf("Skipping optional page action %d (%s): %v", i+1, action.Type, err)
+			continue
+		}
+		return &PageActionError{Index: i, Action: action, Err: err}
+	}
+	return nil
+}
+
+func runPageAction(ctx context.Context, page *rod.Page, action models.PageAction) error {
+	// A wait action only sleeps, its timeout is the wait itself.
+	if action.Type == models.PageActionTypeWait {
+		select {
+		case <-time.After(time.Duration(action.Milliseconds) * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	timeout := defaultPageActionTimeout
+	if action.TimeoutSeconds > 0 {
+		timeout = time.Duration(action.TimeoutSeconds) * time.Second
+	}
+	actionCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := runTimedPageAction(page.Context(actionCtx), action)
+	if err != nil && errors.Is(actionCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+func runTimedPageAction(page *rod.Page, action models.PageAction) error {
+	selector := Selector{Value: action.Selector, Language: action.SelectorLanguage}
+
+	switch action.Type {
+	case models.PageActionTypeClick:
+		elem, err := actionElement(page, selector)
+		if err != nil {
+			return err
+		}
+		return elem.Click(proto.InputMouseButtonLeft, 1)
+
+	case models.PageActionTypeType:
+		elem, err := actionElement(page, selector)
+		if err != nil {
+			return err
+		}
+		// Replace whatever the field already holds.
+		if err := elem.SelectAllText(); err != nil {
+			return err
+		}
+		return elem.Input(action.Value)
+
+	case models.PageActionTypeSelect:
+		elem, err := actionElement(page, selector)
+		if err != nil {
+			return err
+		}
+		return elem.Select([]string{action.Value}, true, rod.SelectorTypeText)
+
+	case models.PageActionTypeWaitForSelector:
+		_, err := actionElement(page, selector)
+		return err
+
+	case models.PageActionTypePressKey:
+		key, ok := pageActionKeys[strings.ToLower(action.Value)]
+		if !ok {
+			return fmt.Errorf("unknown key %q", action.Value)
+		}
+		if !selector.IsEmpty() {
+			elem, err := pageElement(page, selector)
+			if err != nil {
+				return err
+			}
+			if err := elem.Focus(); err != nil {
+				return err
+			}
+		}
+		return page.Keyboard.Type(key)
+
+	case models.PageActionTypeScroll:
+		if selector.IsEmpty() {
+			_, err := page.Eval(`() => window.scrollTo(0, document.documentElement.scrollHeight)`)
+			return err
+		}
+		elem, err := pageElement(page, selector)
+		if err != nil {
+			return err
+		}
+		return elem.ScrollIntoView()
+
+	case models.PageActionTypeEvalJS:
+		if strings.TrimSpace(action.Value) == "" {
+			return fmt.Errorf("evalJs action requires a script")
+		}
+		_, err := page.Eval(jsFunction(action.Value))
+		return err
+
+	default:
+		return fmt.Errorf("unknown page action type: %s", action.Type)
+	}
+}
+
+func actionElement(page *rod.Page, selector Selector) (*rod.Element, error) {
+	if selector.IsEmpty() {
+		return nil, fmt.Errorf("action requires a selector")
+	}
+	return pageElement(page, selector)
+}
+
+var jsFunctionRegex = regexp.MustCompile(`^\s*(async\s+)?(function\b|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)`)
+
+// jsFunction wraps plain statements into a function, rod only evaluates
+// function expressions.
+func jsFunction(script string) string {
+	if jsFunctionRegex.MatchString(script) {
+		return script
+	}
+	return fmt.Sprintf("() => { %s }", script)
+}
+
+var pageActionKeys = map[string]input.Key{
+	"enter":      input.Enter,
+	"tab":        input.Tab,
+	"escape":     input.Escape,
+	"backspace":  input.Backspace,
+	"delete":     input.Delete,
+	"space":      input.Space,
+	"arrowup":    input.ArrowUp,
+	"arrowdown":  input.ArrowDown,
+	"arrowleft":  input.ArrowLeft,
+	"arrowright": input.ArrowRight,
+	"pageup":     input.PageUp,
+	"pagedown":   input.PageDown,
+	"home":       input.Home,
+	"end":        input.End,
+}
@@ -0,0 +1,126 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"scrapeit/internal/models"
+	"testing"
+	"time"
+)
+
+func TestScopePageActions(t *testing.T) {
+	endpoint := models.Endpoint{Actions: []models.PageAction{
+		{ID: "both"},
+		{ID: "main", Scope: models.PageActionScopeMain},
+		{ID: "detail", Scope: models.PageActionScopeDetail},
+	}}
+
+	ids := func(actions []models.PageAction) []string {
+		ids := []string{}
+		for _, action := range actions {
+			ids = append(ids, action.ID)
+		}
+		return ids
+	}
+	if got := ids(mainPageActions(endpoint)); !reflect.DeepEqual(got, []string{"both", "main"}) {
+		t.Errorf("main actions = %v, want [both main]", got)
+	}
+	if got := ids(detailPageActions(endpoint)); !reflect.DeepEqual(got, []string{"both", "detail"}) {
+		t.Errorf("detail actions = %v, want [both detail]", got)
+	}
+}
+
+func TestJSFunction(t *testing.T) {
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{`() => document.title`, `() => document.title`},
+		{`async () => { await x() }`, `async () => { await x() }`},
+		{`function () { return 1 }`, `function () { return 1 }`},
+		{`el => el.click()`, `el => el.click()`},
+		{`document.querySelector(".accept").click()`, `() => { document.querySelector(".accept").click() }`},
+	}
+
+	for _, tt := range tests {
+		if got := jsFunction(tt.script); got != tt.want {
+			t.Errorf("jsFunction(%q) = %q, want %q", tt.script, got, tt.want)
+		}
+	}
+}
+
+const actionsPage = `<html><body>
+<input id="q" value="old">
+<select id="color"><option>Red</option><option>Blue</option></select>
+<button id="go" onclick="setTimeout(() => {
+	const out = document.createElement('p')
+	out.id = 'out'
+	out.textContent = document.getElementById('q').value + '/' + document.getElementById('color').value
+	document.body.appendChild(out)
+}, 200)">Go</button>
+</body></html>`
+
+func TestRunPageActions(t *testing.T) {
+	browser := testBrowser(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, actionsPage)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("actions run in order", func(t *testing.T) {
+		page := browser.MustPage(server.URL).MustWaitLoad()
+		defer page.Close()
+
+		err := runPageActions(context.Background(), page, []models.PageAction{
+			{Type: models.PageActionTypeType, Selector: "#q", Value: "shoes"},
+			{Type: models.PageActionTypeSelect, Selector: "#color", Value: "Blue"},
+			{Type: models.PageActionTypeClick, Selector: `//button[text()="Go"]`, SelectorLanguage: models.SelectorLanguageXPath},
+			{Type: models.PageActionTypeWaitForSelector, Selector: "#out"},
+			{Type: models.PageActionTypeClick, Selector: "#missing", Optional: true, TimeoutSeconds: 1},
+			{Type: models.PageActionTypeEvalJS, Value: `document.body.dataset.done = document.getElementById("out").textContent`},
+		})
+		if err != nil {
+			t.Fatalf("runPageActions() error: %v", err)
+		}
+		if got := page.MustEval(`() => document.body.dataset.done`).Str(); got != "shoes/Blue" {
+			t.Errorf("result = %q, want shoes/Blue", got)
+		}
+	})
+
+	t.Run("a required action stops the run", func(t *testing.T) {
+		page := browser.MustPage(server.URL).MustWaitLoad()
+		defer page.Close()
+
+		err := runPageActions(context.Background(), page, []models.PageAction{
+			{Type: models.PageActionTypeWait, Milliseconds: 10},
+			{Type: models.PageActionTypeClick, Selector: "#missing", TimeoutSeconds: 1},
+			{Type: models.PageActionTypeEvalJS, Value: `document.body.dataset.done = "yes"`},
+		})
+		var actionErr *PageActionError
+		if !errors.As(err, &actionErr) || actionErr.Index != 1 {
+			t.Fatalf("runPageActions() error = %v, want a PageActionError for action 2", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("runPageActions() error = %v, want it to wrap the timeout", err)
+		}
+		if got := page.MustEval(`() => document.body.dataset.done || ""`).Str(); got != "" {
+			t.Error("actions after the failing one were run")
+		}
+	})
+
+	t.Run("cancelled wait", func(t *testing.T) {
+		page := browser.MustPage(server.URL).MustWaitLoad()
+		defer page.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := runPageActions(ctx, page, []models.PageAction{{Type: models.PageActionTypeWait, Milliseconds: 10000}})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("runPageActions() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
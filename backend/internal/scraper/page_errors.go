@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// PageErrorKind classifies why a page could not be opened.
+type PageErrorKind string
+
+const (
+	// PageErrorSolverUnavailable means the challenge solver could not be
+	// reached or did not answer usefully.
+	PageErrorSolverUnavailable PageErrorKind = "solverUnavailable"
+	// PageErrorBlocked means the site refused the request, by status or by
+	// banning the proxy.
+	PageErrorBlocked PageErrorKind = "blocked"
+	PageErrorTimeout PageErrorKind = "timeout"
+	// PageErrorSelectorMissing means the page loaded but the element waited
+	// for never showed up.
+	PageErrorSelectorMissing PageErrorKind = "selectorMissing"
+)
+
+// PageError is returned when a browser page could not be opened.
+type PageError struct {
+	Kind PageErrorKind
+	URL  string
+	Err  error
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("%s on %s: %v", e.Kind, e.URL, e.Err)
+}
+
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// isTimeout reports whether err is a deadline or a network timeout.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// navigationError classifies an error of navigatePage. Cancelled runs keep
+// their context error.
+func navigationError(url string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if isTimeout(err) {
+		return &PageError{Kind: PageErrorTimeout, URL: url, Err: err}
+	}
+	return err
+}
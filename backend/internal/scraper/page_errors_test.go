@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// pageErrorKind returns the kind of the PageError in err's chain, or "" when
+// there is none.
+func pageErrorKind(err error) PageErrorKind {
+	var pageErr *PageError
+	if errors.As(err, &pageErr) {
+		return pageErr.Kind
+	}
+	return ""
+}
+
+func TestNavigationError(t *testing.T) {
+	boom := errors.New("net::ERR_NAME_NOT_RESOLVED")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind PageErrorKind
+		wantErr  error
+	}{
+		{"deadline", context.DeadlineExceeded, PageErrorTimeout, context.DeadlineExceeded},
+		{"wrapped deadline", fmt.Errorf("navigating: %w", context.DeadlineExceeded), PageErrorTimeout, context.DeadlineExceeded},
+		{"network timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, PageErrorTimeout, nil},
+		{"cancelled run", context.Canceled, "", context.Canceled},
+		{"other error", boom, "", boom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := navigationError("https://example.com", tt.err)
+			if kind := pageErrorKind(err); kind != tt.wantKind {
+				t.Errorf("navigationError() kind = %q, want %q", kind, tt.wantKind)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("navigationError() = %v, want it to wrap %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPageError(t *testing.T) {
+	cause := &HTTPStatusError{URL: "https://example.com", StatusCode: 403}
+	err := fmt.Errorf("opening page: %w", &PageError{Kind: PageErrorBlocked, URL: "https://example.com", Err: cause})
+
+	if kind := pageErrorKind(err); kind != PageErrorBlocked {
+		t.Errorf("kind = %q, want %q", kind, PageErrorBlocked)
+	}
+	var statusErr *HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 403 {
+		t.Errorf("errors.As(%v) did not find the status error", err)
+	}
+	if want := "opening page: blocked on https://example.com: " + cause.Error(); err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,220 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"scrapeit/internal/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	pageFailuresCollection = "page_failures"
+	pageFailuresBucket     = "page_failure_snapshots"
+	// maxFailuresPerEndpoint is how many failures are kept per endpoint, the
+	// older ones are removed with their snapshots.
+	maxFailuresPerEndpoint = 50
+	failureCaptureTimeout  = 15 * time.Second
+	// maxFailureHTMLBytes caps the stored HTML of a failed page. The start of
+	// the document is kept, it usually tells why the page failed.
+	maxFailureHTMLBytes = 1 << 20
+	// maxFailureScreenshotBytes drops screenshots larger than it, e.g. of
+	// pages with a huge viewport.
+	maxFailureScreenshotBytes = 5 << 20
+)
+
+func pageFailureStorage() (*mongo.Collection, *gridfs.Bucket, error) {
+	client, err := models.GetDbClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	db := client.Database("scrapeit")
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(pageFailuresBucket))
+	if err != nil {
+		return nil, nil, err
+	}
+	return db.Collection(pageFailuresCollection), bucket, nil
+}
+
+// failPage captures the page for debugging, closes it and returns cause.
+// Cancelled runs are not captured, nothing failed on their pages.
+func failPage(ctx context.Context, page *rod.Page, url string, cause error) error {
+	if !errors.Is(cause, context.Canceled) {
+		captureFailure(ctx, page, url, cause)
+	}
+	closePage(page)
+	return cause
+}
+
+// captureFailure stores a screenshot and the HTML of page, tagged with the
+// run from ctx. Errors are only logged, the failure itself is what matters.
+func captureFailure(ctx context.Context, page *rod.Page, url string, cause error) {
+	// Timeouts are the usual reason to capture a page, so the capture gets
+	// a deadline of its own.
+	captureCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failureCaptureTimeout)
+	defer cancel()
+
+	collection, bucket, err := pageFailureStorage()
+	if err != nil {
+		log.Printf("Error capturing failure on %s: %v", url, err)
+		return
+	}
+	// Uploads do not take a context, they get the capture's deadline.
+	deadline, _ := captureCtx.Deadline()
+	if err := bucket.SetWriteDeadline(deadline); err != nil {
+		log.Printf("Error capturing failure on %s: %v", url, err)
+		return
+	}
+
+	info := runInfoFrom(ctx)
+	failure := models.PageFailure{
+		ID:         primitive.NewObjectID(),
+		GroupID:    info.groupId,
+		EndpointID: info.endpointId,
+		RunID:      info.runId,
+		URL:        url,
+		Error:      cause.Error(),
+		Created:    primitive.NewDateTimeFromTime(time.Now()),
+	}
+	var pageErr *PageError
+	if errors.As(cause, &pageErr) {
+		failure.Kind = string(pageErr.Kind)
+	}
+
+	metadata := bson.M{"failureId": failure.ID, "groupId": info.groupId, "endpointId": info.endpointId, "runId": info.runId, "url": url}
+	capturePage := page.Context(captureCtx)
+	if img, err := capturePage.Screenshot(false, nil); err != nil {
+		log.Printf("Error taking screenshot of %s: %v", url, err)
+	} else if len(img) > maxFailureScreenshotBytes {
+		log.Printf("Not storing screenshot of %s, it has %d bytes", url, len(img))
+	} else if id, err := bucket.UploadFromStream(failure.ID.Hex()+".png", bytes.NewReader(img), options.GridFSUpload().SetMetadata(metadata)); err != nil {
+		log.Printf("Error storing screenshot of %s: %v", url, err)
+	} else {
+		failure.ScreenshotID = &id
+	}
+	if html, err := capturePage.HTML(); err != nil {
+		log.Printf("Error reading html of %s: %v", url, err)
+	} else if id, err := bucket.UploadFromStream(failure.ID.Hex()+".html", strings.NewReader(truncateSnapshot(html, maxFailureHTMLBytes)), options.GridFSUpload().SetMetadata(metadata)); err != nil {
+		log.Printf("Error storing html of %s: %v", url, err)
+	} else {
+		failure.HTMLID = &id
+	}
+
+	if _, err := collection.InsertOne(captureCtx, failure); err != nil {
+		log.Printf("Error storing failure on %s: %v", url, err)
+		return
+	}
+	log.Printf("Captured failure %s on %s", failure.ID.Hex(), url)
+
+	if info.endpointId != "" {
+		pruneFailures(captureCtx, info.groupId, info.endpointId)
+	}
+}
+
+// truncateSnapshot cuts html to at most limit bytes plus a note saying so,
+// without splitting a character.
+func truncateSnapshot(html string, limit int) string {
+	if len(html) <= limit {
+		return html
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(html[cut]) {
+		cut--
+	}
+	return html[:cut] + fmt.Sprintf("\n<!-- truncated, %d of %d bytes kept -->\n", cut, len(html))
+}
+
+// pruneFailures removes the endpoint's failures beyond the newest
+// maxFailuresPerEndpoint.
+func pruneFailures(ctx context.Context, groupId, endpointId string) {
+	collection, _, err := pageFailureStorage()
+	if err != nil {
+		return
+	}
+	opts := options.Find().SetSort(bson.M{"created": -1}).SetSkip(maxFailuresPerEndpoint)
+	result, err := collection.Find(ctx, bson.M{"groupId": groupId, "endpointId": endpointId}, opts)
+	if err != nil {
+		log.Printf("Error finding old failures: %v", err)
+		return
+	}
+	var old []models.PageFailure
+	if err := result.All(ctx, &old); err != nil {
+		log.Printf("Error reading old failures: %v", err)
+		return
+	}
+	for _, failure := range old {
+		if err := DeletePageFailure(ctx, failure); err != nil {
+			log.Printf("Error removing old failure %s: %v", failure.ID.Hex(), err)
+		}
+	}
+}
+
+// DeletePageFailure removes the failure and its snapshots.
+func DeletePageFailure(ctx context.Context, failure models.PageFailure) error {
+	collection, bucket, err := pageFailureStorage()
+	if err != nil {
+		return err
+	}
+	for _, id := range []*primitive.ObjectID{failure.ScreenshotID, failure.HTMLID} {
+		if id == nil {
+			continue
+		}
+		if err := bucket.DeleteContext(ctx, *id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return fmt.Errorf("error deleting snapshot %s: %w", id.Hex(), err)
+		}
+	}
+	_, err = collection.DeleteOne(ctx, bson.M{"_id": failure.ID})
+	return err
+}
+
+// DeletePageFailures removes the failures of a group, or only those of the
+// given endpoints of it, with their snapshots.
+func DeletePageFailures(ctx context.Context, groupId string, endpointIds ...string) error {
+	collection, _, err := pageFailureStorage()
+	if err != nil {
+		return err
+	}
+	filter := bson.M{"groupId": groupId}
+	if len(endpointIds) > 0 {
+		filter["endpointId"] = bson.M{"$in": endpointIds}
+	}
+	result, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	var failures []models.PageFailure
+	if err := result.All(ctx, &failures); err != nil {
+		return err
+	}
+	for _, failure := range failures {
+		if err := DeletePageFailure(ctx, failure); err != nil {
+			return fmt.Errorf("error deleting failure %s: %w", failure.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// PageFailureSnapshot returns the content of a stored screenshot or HTML
+// snapshot.
+func PageFailureSnapshot(ctx context.Context, id primitive.ObjectID) ([]byte, error) {
+	_, bucket, err := pageFailureStorage()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := bucket.DownloadToStream(id, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateSnapshot(t *testing.T) {
+	tests := []struct {
+		name  string
+		html  string
+		limit int
+		want  string
+	}{
+		{"small page", "<p>ok</p>", 20, "<p>ok</p>"},
+		{"exactly at the limit", "<p>ok</p>", 9, "<p>ok</p>"},
+		{"large page", "<p>" + strings.Repeat("a", 20) + "</p>", 10, "<p>aaaaaaa\n<!-- truncated, 10 of 27 bytes kept -->\n"},
+		{"multi byte character at the cut", "<p>ééé</p>", 6, "<p>é\n<!-- truncated, 5 of 13 bytes kept -->\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateSnapshot(tt.html, tt.limit)
+			if got != tt.want {
+				t.Errorf("truncateSnapshot() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateSnapshot() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestTruncateSnapshotBound(t *testing.T) {
+	html := strings.Repeat("<div>€</div>", maxFailureHTMLBytes)
+	got := truncateSnapshot(html, maxFailureHTMLBytes)
+	if len(got) > maxFailureHTMLBytes+100 {
+		t.Errorf("snapshot has %d bytes, want at most about %d", len(got), maxFailureHTMLBytes)
+	}
+	if !strings.HasPrefix(html, strings.SplitN(got, "\n<!-- truncated", 2)[0]) {
+		t.Error("snapshot does not keep the start of the page")
+	}
+}
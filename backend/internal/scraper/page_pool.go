@@ -0,0 +1,269 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"scrapeit/internal/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// BEGIN: concurrency config
+
+// concurrencyConfig holds the limits that decide how many browser tabs a
+// scrape can hold at once. Tabs are only freed when a page is closed, so the
+// tab limit has to fit every endpoint holding its listing page and its
+// detail pages at the same time, or the endpoints wait on each other.
+type concurrencyConfig struct {
+	maxEndpoints      int
+	detailConcurrency int
+	maxTabs           int
+	leakWarnAfter     time.Duration
+	leakReclaimAfter  time.Duration
+}
+
+var (
+	concurrency     concurrencyConfig
+	concurrencyOnce sync.Once
+)
+
+func getConcurrency() concurrencyConfig {
+	concurrencyOnce.Do(func() {
+		maxEndpoints := max(envInt("SCRAPE_MAX_CONCURRENT_ENDPOINTS", 4), 1)
+		detailConcurrency := max(envInt("SCRAPE_DETAIL_CONCURRENCY", 2), 1)
+		needed := maxEndpoints * (detailConcurrency + 1)
+		maxTabs := envInt("SCRAPE_MAX_TABS", needed)
+		if maxTabs < needed {
+			log.Printf("SCRAPE_MAX_TABS %d is below %d endpoints * (%d detail pages + 1 listing page), scrapes may wait for tabs until they time out",
+				maxTabs, maxEndpoints, detailConcurrency)
+		}
+		concurrency = concurrencyConfig{
+			maxEndpoints:      maxEndpoints,
+			detailConcurrency: detailConcurrency,
+			maxTabs:           max(maxTabs, 1),
+			leakWarnAfter:     time.Duration(envInt("SCRAPE_TAB_LEAK_WARN_MINUTES", 5)) * time.Minute,
+			// Long scrapes hold their listing page legitimately, so held tabs
+			// are only closed when reclaiming is configured.
+			leakReclaimAfter: time.Duration(envInt("SCRAPE_TAB_LEAK_RECLAIM_MINUTES", 0)) * time.Minute,
+		}
+		log.Printf("Concurrency: %d endpoints, %d detail pages per endpoint, %d browser tabs",
+			concurrency.maxEndpoints, concurrency.detailConcurrency, concurrency.maxTabs)
+	})
+	return concurrency
+}
+
+// MaxConcurrentEndpoints is how many endpoints are scraped at the same time.
+func MaxConcurrentEndpoints() int {
+	return getConcurrency().maxEndpoints
+}
+
+// detailConcurrency is how many detail pages a single endpoint opens at once.
+func detailConcurrency() int {
+	return getConcurrency().detailConcurrency
+}
+
+var endpointRuns = struct {
+	once  sync.Once
+	slots chan struct{}
+}{}
+
+// acquireEndpointRun waits until another endpoint may be scraped or ctx is
+// done and returns the func that frees its slot.
+func acquireEndpointRun(ctx context.Context) (func(), error) {
+	endpointRuns.once.Do(func() {
+		endpointRuns.slots = make(chan struct{}, MaxConcurrentEndpoints())
+	})
+	select {
+	case endpointRuns.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free endpoint slot: %w", ctx.Err())
+	}
+	return func() { <-endpointRuns.slots }, nil
+}
+
+// END: concurrency config
+
+// BEGIN: tab pool
+
+// pageLease is a tab handed out by the pool.
+type pageLease struct {
+	page     *rod.Page
+	acquired time.Time
+	caller   string
+	warned   bool
+}
+
+// tabPool limits the browser tabs open at once and keeps closed tabs of the
+// default browser context around for reuse. Tabs of proxy contexts are
+// destroyed with their context instead.
+type tabPool struct {
+	once  sync.Once
+	slots chan struct{}
+
+	mu     sync.Mutex
+	idle   []*rod.Page
+	leases map[proto.TargetTargetID]*pageLease
+}
+
+var tabs = &tabPool{}
+
+func (p *tabPool) init() {
+	p.once.Do(func() {
+		p.slots = make(chan struct{}, getConcurrency().maxTabs)
+		p.leases = map[proto.TargetTargetID]*pageLease{}
+		go p.watchLeaks()
+	})
+}
+
+// acquire waits for a free tab and returns a page, reusing an idle one when
+// no proxy is needed.
+func (p *tabPool) acquire(ctx context.Context, browser *rod.Browser, proxy *models.Proxy) (*rod.Page, error) {
+	p.init()
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free browser tab: %w", ctx.Err())
+	}
+
+	page, err := p.open(browser, proxy)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.leases[page.TargetID] = &pageLease{page: page, acquired: time.Now(), caller: leaseCaller()}
+	p.mu.Unlock()
+	return page, nil
+}
+
+func (p *tabPool) open(browser *rod.Browser, proxy *models.Proxy) (*rod.Page, error) {
+	for {
+		p.mu.Lock()
+		if len(p.idle) == 0 {
+			p.mu.Unlock()
+			break
+		}
+		page := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.mu.Unlock()
+
+		// An idle tab still counts against the limit, so a proxied page
+		// replaces one.
+		if proxy != nil {
+			page.Close()
+			break
+		}
+		if _, err := page.Info(); err != nil {
+			continue
+		}
+		return page, nil
+	}
+	return newStealthPage(browser, proxy)
+}
+
+// release frees the page's tab. Pages in the default context are reset and
+// kept for reuse.
+func (p *tabPool) release(page *rod.Page) error {
+	p.init()
+	p.mu.Lock()
+	_, leased := p.leases[page.TargetID]
+	delete(p.leases, page.TargetID)
+	p.mu.Unlock()
+
+	if !leased {
+		// Closed twice, reclaimed as leaked or not opened by the pool.
+		return destroyPage(page)
+	}
+	defer func() { <-p.slots }()
+
+	if page.Browser().BrowserContextID != "" {
+		return destroyPage(page)
+	}
+	if err := resetPage(page); err != nil {
+		log.Printf("Error resetting page, closing it: %v", err)
+		return page.Close()
+	}
+
+	p.mu.Lock()
+	p.idle = append(p.idle, page)
+	p.mu.Unlock()
+	return nil
+}
+
+// resetPage leaves the page blank and drops the user agent and emulation
+// overrides of its last user, so the next user starts from a clean tab.
+func resetPage(page *rod.Page) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	page = page.Context(ctx)
+
+	// An empty user agent removes the override.
+	if err := (proto.NetworkSetUserAgentOverride{}).Call(page); err != nil {
+		return fmt.Errorf("error resetting user agent: %w", err)
+	}
+	if err := (proto.EmulationClearDeviceMetricsOverride{}).Call(page); err != nil {
+		return fmt.Errorf("error resetting viewport: %w", err)
+	}
+	return page.Navigate("about:blank")
+}
+
+// watchLeaks reports tabs held for long. With SCRAPE_TAB_LEAK_RECLAIM_MINUTES
+// set it also closes tabs held longer than that.
+func (p *tabPool) watchLeaks() {
+	config := getConcurrency()
+	for range time.Tick(time.Minute) {
+		var reclaimed []*rod.Page
+
+		p.mu.Lock()
+		for id, lease := range p.leases {
+			held := time.Since(lease.acquired)
+			if config.leakReclaimAfter > 0 && held > config.leakReclaimAfter {
+				log.Printf("Closing browser tab held for %s by %s, it was never closed", held.Round(time.Second), lease.caller)
+				delete(p.leases, id)
+				reclaimed = append(reclaimed, lease.page)
+			} else if !lease.warned && config.leakWarnAfter > 0 && held > config.leakWarnAfter {
+				log.Printf("Browser tab held for %s by %s, it may have been leaked", held.Round(time.Second), lease.caller)
+				lease.warned = true
+			}
+		}
+		p.mu.Unlock()
+
+		for _, page := range reclaimed {
+			destroyPage(page)
+			<-p.slots
+		}
+	}
+}
+
+// leaseCaller names the first function outside the page loading code that
+// asked for the tab.
+func leaseCaller() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		switch name {
+		case "scraper.getStealthPage", "scraper.GetStealthPage", "scraper.(*browserFetcher).Fetch", "scraper.(*robotsFetcher).Fetch":
+		default:
+			return fmt.Sprintf("%s (%s:%d)", name, frame.File[strings.LastIndex(frame.File, "/")+1:], frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// closePage gives the page's tab back to the pool.
+func closePage(page *rod.Page) error {
+	return tabs.release(page)
+}
+
+// END: tab pool
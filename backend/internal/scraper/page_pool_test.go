@@ -0,0 +1,47 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireEndpointRunCancelled(t *testing.T) {
+	var releases []func()
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
+	for i := 0; i < MaxConcurrentEndpoints(); i++ {
+		release, err := acquireEndpointRun(context.Background())
+		if err != nil {
+			t.Fatalf("acquireEndpointRun() error: %v", err)
+		}
+		releases = append(releases, release)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := acquireEndpointRun(ctx)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("acquireEndpointRun() returned %v while all slots are taken", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("acquireEndpointRun() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("acquireEndpointRun() did not return after cancel")
+	}
+}
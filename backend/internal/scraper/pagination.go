@@ -0,0 +1,388 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"scrapeit/internal/models"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	defaultMaxPages = 50
+	// maxOpenEndedFailures is how many pages in a row may fail before open
+	// ended pagination gives up.
+	maxOpenEndedFailures  = 3
+	nextPageChangeTimeout = 15 * time.Second
+	defaultTimeBudget     = 5 * time.Minute
+	listingGrowTimeout    = 8 * time.Second
+)
+
+// errStopPagination can be returned by a page visitor to end the pagination
+// early without reporting an error.
+var errStopPagination = errors.New("stop pagination")
+
+// pageVisitor is called once for every listing page with the main elements
+// found on it.
+type pageVisitor func(doc Document, elements []Element) error
+
+// visitListingPages walks the endpoint's listing pages according to its
+// pagination config and returns how many pages were visited. Pages that fail
+// to load are logged, counted in stats and skipped instead of aborting the
+// whole run.
+func visitListingPages(ctx context.Context, fetcher Fetcher, endpoint models.Endpoint, stats *runStats, visit pageVisitor) (int, error) {
+	switch models.PaginationConfigType(endpoint.PaginationConfig.Type) {
+	case models.PaginationConfigTypeNextButton:
+		return visitNextButtonPages(ctx, fetcher, endpoint, visit)
+	case models.PaginationConfigTypeInfinite, models.PaginationConfigTypeLoadMore:
+		// The listing grows on a single page.
+		if err := visitGrowingListing(ctx, fetcher, endpoint, visit); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	default:
+		return visitURLPages(ctx, fetcher, endpoint, stats, visit)
+	}
+}
+
+func visitURLPages(ctx context.Context, fetcher Fetcher, endpoint models.Endpoint, stats *runStats, visit pageVisitor) (int, error) {
+	config := endpoint.PaginationConfig
+	maxPages := config.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	// Failed pages count against maxPages as well, so an open ended listing
+	// whose pages all fail still ends.
+	pagesVisited := 0
+	attempts := 0
+	failures := 0
+	for i := config.Start; config.OpenEnded || i <= config.End; i += config.Step {
+		if config.OpenEnded && attempts >= maxPages {
+			fmt.Printf("Reached max pages (%d) for %s\n", maxPages, endpoint.URL)
+			break
+		}
+		if ctx.Err() != nil {
+			return pagesVisited, ctx.Err()
+		}
+		attempts++
+
+		urlWithPagination := buildPaginationURL(endpoint.URL, config, i)
+		fmt.Println("Scraping URL: ", urlWithPagination)
+
+		visited, err := visitURLPage(ctx, fetcher, endpoint, urlWithPagination, visit)
+		if visited {
+			pagesVisited++
+		}
+		if errors.Is(err, errStopPagination) {
+			break
+		}
+		if err != nil {
+			// In open ended mode a page that does not load is the end of
+			// the listing, the main element never showed up on it.
+			if config.OpenEnded && pagesVisited > 0 {
+				fmt.Printf("Stopping open ended pagination at %s: %v\n", urlWithPagination, err)
+				break
+			}
+			if ctx.Err() != nil {
+				return pagesVisited, err
+			}
+			log.Printf("Error scraping page %s, skipping it: %v", urlWithPagination, err)
+			stats.recordPageSkipped()
+			failures++
+			if config.OpenEnded && failures >= maxOpenEndedFailures {
+				fmt.Printf("Stopping open ended pagination of %s after %d failed pages\n", endpoint.URL, failures)
+				break
+			}
+		} else {
+			failures = 0
+		}
+
+		if config.Step <= 0 {
+			break
+		}
+	}
+	return pagesVisited, nil
+}
+
+// visitURLPage loads a single listing page and reports whether it was
+// loaded. Open ended pagination stops on a page without main elements.
+func visitURLPage(ctx context.Context, fetcher Fetcher, endpoint models.Endpoint, url string, visit pageVisitor) (bool, error) {
+	doc, err := fetcher.Fetch(ctx, url, mainElementSelector(endpoint), mainPageActions(endpoint))
+	if err != nil {
+		return false, fmt.Errorf("error getting page: %w", err)
+	}
+	defer doc.Close()
+
+	doc.ScrollToBottom()
+	doc.WaitStable()
+
+	elements, err := doc.Elements(mainElementSelector(endpoint))
+	if err != nil {
+		return true, fmt.Errorf("error finding elements: %w", err)
+	}
+
+	if endpoint.PaginationConfig.OpenEnded && len(elements) == 0 {
+		fmt.Printf("No main elements on %s, stopping pagination\n", url)
+		return true, errStopPagination
+	}
+
+	return true, visit(doc, elements)
+}
+
+// BEGIN: next button pagination
+
+func visitNextButtonPages(ctx context.Context, fetcher Fetcher, endpoint models.Endpoint, visit pageVisitor) (int, error) {
+	config := endpoint.PaginationConfig
+	if nextButtonSelector(config).IsEmpty() {
+		return 0, fmt.Errorf("next button pagination requires a next button selector")
+	}
+
+	doc, err := fetcher.Fetch(ctx, endpoint.URL, mainElementSelector(endpoint), mainPageActions(endpoint))
+	if err != nil {
+		return 0, fmt.Errorf("error getting page: %w", err)
+	}
+	defer doc.Close()
+
+	browserDoc, ok := doc.(*browserDocument)
+	if !ok {
+		return 0, fmt.Errorf("next button pagination requires the browser fetcher")
+	}
+
+	maxPages := config.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	for pageNumber := 1; ; pageNumber++ {
+		doc.ScrollToBottom()
+		doc.WaitStable()
+
+		elements, err := doc.Elements(mainElementSelector(endpoint))
+		if err != nil {
+			return pageNumber - 1, fmt.Errorf("error finding elements: %w", err)
+		}
+
+		fmt.Printf("Scraping page %d of %s\n", pageNumber, endpoint.URL)
+		if err := visit(doc, elements); err != nil {
+			if errors.Is(err, errStopPagination) {
+				return pageNumber, nil
+			}
+			return pageNumber, err
+		}
+
+		if pageNumber >= maxPages {
+			fmt.Printf("Reached max pages (%d) for %s\n", maxPages, endpoint.URL)
+			return pageNumber, nil
+		}
+
+		if ctx.Err() != nil {
+			return pageNumber, ctx.Err()
+		}
+
+		if err := waitForPageStep(ctx, fetcher, doc); err != nil {
+			return pageNumber, err
+		}
+		clicked, err := clickNextButton(browserDoc.page, nextButtonSelector(config), mainElementSelector(endpoint))
+		if err != nil {
+			return pageNumber, fmt.Errorf("error going to next page: %w", err)
+		}
+		if !clicked {
+			return pageNumber, nil
+		}
+	}
+}
+
+// clickNextButton clicks the next button and waits for the listing to
+// change. It returns false when the button is missing or disabled.
+func clickNextButton(page *rod.Page, nextButton Selector, mainElementSelector Selector) (bool, error) {
+	button, err := findEnabledButton(page, nextButton)
+	if err != nil {
+		return false, err
+	}
+	if button == nil {
+		fmt.Println("Next button is missing or disabled, stopping pagination")
+		return false, nil
+	}
+
+	before := firstElementHTML(page, mainElementSelector)
+
+	if err := clickButton(button); err != nil {
+		return false, err
+	}
+
+	deadline := time.Now().Add(nextPageChangeTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(250 * time.Millisecond)
+		after := firstElementHTML(page, mainElementSelector)
+		if after != "" && after != before {
+			return true, nil
+		}
+	}
+
+	fmt.Println("Listing did not change after clicking next button, stopping pagination")
+	return false, nil
+}
+
+// findEnabledButton returns the element matching selector, or nil when it is
+// not on the page or looks disabled.
+func findEnabledButton(page *rod.Page, selector Selector) (*rod.Element, error) {
+	has, button, err := pageHas(page, selector)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+
+	disabled, err := button.Eval(`() => this.disabled === true ||
+		this.getAttribute("aria-disabled") === "true" ||
+		this.classList.contains("disabled")`)
+	if err != nil {
+		return nil, err
+	}
+	if disabled.Value.Bool() {
+		return nil, nil
+	}
+	return button, nil
+}
+
+func clickButton(button *rod.Element) error {
+	if err := button.ScrollIntoView(); err != nil {
+		return err
+	}
+	return button.Click(proto.InputMouseButtonLeft, 1)
+}
+
+func firstElementHTML(page *rod.Page, selector Selector) string {
+	has, elem, err := pageHas(page, selector)
+	if err != nil || !has {
+		return ""
+	}
+	html, err := elem.HTML()
+	if err != nil {
+		return ""
+	}
+	return html
+}
+
+// END: next button pagination
+
+// BEGIN: infinite scroll and load more pagination
+
+// visitGrowingListing keeps one page open and grows its listing by scrolling
+// or clicking the load more button. Only the elements that appeared since the
+// previous step are handed to visit.
+func visitGrowingListing(ctx context.Context, fetcher Fetcher, endpoint models.Endpoint, visit pageVisitor) error {
+	config := endpoint.PaginationConfig
+	isLoadMore := models.PaginationConfigType(config.Type) == models.PaginationConfigTypeLoadMore
+	if isLoadMore && loadMoreSelector(config).IsEmpty() {
+		return fmt.Errorf("load more pagination requires a load more selector")
+	}
+
+	doc, err := fetcher.Fetch(ctx, endpoint.URL, mainElementSelector(endpoint), mainPageActions(endpoint))
+	if err != nil {
+		return fmt.Errorf("error getting page: %w", err)
+	}
+	defer doc.Close()
+
+	browserDoc, ok := doc.(*browserDocument)
+	if !ok {
+		return fmt.Errorf("%s pagination requires the browser fetcher", config.Type)
+	}
+	page := browserDoc.page
+
+	timeBudget := defaultTimeBudget
+	if config.TimeBudgetSeconds > 0 {
+		timeBudget = time.Duration(config.TimeBudgetSeconds) * time.Second
+	}
+	deadline := time.Now().Add(timeBudget)
+
+	doc.WaitStable()
+
+	seen := 0
+	for step := 1; ; step++ {
+		elements, err := doc.Elements(mainElementSelector(endpoint))
+		if err != nil {
+			return fmt.Errorf("error finding elements: %w", err)
+		}
+
+		if len(elements) > seen {
+			newElements := elements[seen:]
+			if config.TargetItemCount > 0 && len(elements) > config.TargetItemCount {
+				newElements = elements[seen:config.TargetItemCount]
+			}
+			fmt.Printf("Step %d: %d new elements on %s\n", step, len(newElements), endpoint.URL)
+			if err := visit(doc, newElements); err != nil {
+				if errors.Is(err, errStopPagination) {
+					return nil
+				}
+				return err
+			}
+			seen += len(newElements)
+		}
+
+		if config.TargetItemCount > 0 && seen >= config.TargetItemCount {
+			fmt.Printf("Reached target item count (%d) for %s\n", config.TargetItemCount, endpoint.URL)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			fmt.Printf("Time budget exceeded for %s\n", endpoint.URL)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := waitForPageStep(ctx, fetcher, doc); err != nil {
+			return err
+		}
+
+		if isLoadMore {
+			button, err := findEnabledButton(page, loadMoreSelector(config))
+			if err != nil {
+				return fmt.Errorf("error finding load more button: %w", err)
+			}
+			if button == nil {
+				fmt.Println("Load more button is missing or disabled, stopping pagination")
+				return nil
+			}
+			if err := clickButton(button); err != nil {
+				return fmt.Errorf("error clicking load more button: %w", err)
+			}
+		} else {
+			if _, err := page.Eval(`() => window.scrollTo(0, document.documentElement.scrollHeight)`); err != nil {
+				return fmt.Errorf("error scrolling: %w", err)
+			}
+		}
+
+		if !waitForElementCountAbove(page, mainElementSelector(endpoint), len(elements), deadline) {
+			fmt.Println("Main element count stopped growing, stopping pagination")
+			return nil
+		}
+	}
+}
+
+// waitForElementCountAbove polls until more than count elements match the
+// selector. It gives up after listingGrowTimeout or at the deadline.
+func waitForElementCountAbove(page *rod.Page, selector Selector, count int, deadline time.Time) bool {
+	waitUntil := time.Now().Add(listingGrowTimeout)
+	if deadline.Before(waitUntil) {
+		waitUntil = deadline
+	}
+	for time.Now().Before(waitUntil) {
+		time.Sleep(250 * time.Millisecond)
+		elements, err := pageElements(page, selector)
+		if err == nil && len(elements) > count {
+			page.WaitStable(time.Second)
+			return true
+		}
+	}
+	return false
+}
+
+// END: infinite scroll and load more pagination
@@ -0,0 +1,322 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"scrapeit/internal/models"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// listingServer serves ?page=N with itemsPerPage items for the pages in
+// [1, lastPage] and a 404 for every other page. It counts the requests.
+func listingServer(t *testing.T, lastPage int, itemsPerPage int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 || page > lastPage {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<html><body><ul>")
+		for i := 0; i < itemsPerPage; i++ {
+			fmt.Fprintf(w, `<li class="item">%d-%d</li>`, page, i)
+		}
+		fmt.Fprint(w, "</ul></body></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestVisitURLPages(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastPage     int
+		config       models.PaginationConfig
+		wantVisited  int
+		wantRequests int32
+		wantSkipped  int
+	}{
+		{
+			name:         "fixed range",
+			lastPage:     5,
+			config:       models.PaginationConfig{Start: 1, End: 3, Step: 1},
+			wantVisited:  3,
+			wantRequests: 3,
+		},
+		{
+			name:         "fixed range skips failed pages",
+			lastPage:     2,
+			config:       models.PaginationConfig{Start: 1, End: 4, Step: 1},
+			wantVisited:  2,
+			wantRequests: 4,
+			wantSkipped:  2,
+		},
+		{
+			name:         "open ended stops after the last page",
+			lastPage:     3,
+			config:       models.PaginationConfig{Start: 1, Step: 1, OpenEnded: true},
+			wantVisited:  3,
+			wantRequests: 4,
+		},
+		{
+			name:         "open ended caps pages",
+			lastPage:     10,
+			config:       models.PaginationConfig{Start: 1, Step: 1, OpenEnded: true, MaxPages: 2},
+			wantVisited:  2,
+			wantRequests: 2,
+		},
+		{
+			name:         "open ended gives up on failing pages",
+			lastPage:     0,
+			config:       models.PaginationConfig{Start: 1, Step: 1, OpenEnded: true},
+			wantRequests: maxOpenEndedFailures,
+			wantSkipped:  maxOpenEndedFailures,
+		},
+		{
+			name:         "failed pages count against max pages",
+			lastPage:     0,
+			config:       models.PaginationConfig{Start: 1, Step: 1, OpenEnded: true, MaxPages: 2},
+			wantRequests: 2,
+			wantSkipped:  2,
+		},
+		{
+			name:         "failing page without step is loaded once",
+			lastPage:     0,
+			config:       models.PaginationConfig{Start: 1, Step: 0, OpenEnded: true},
+			wantRequests: 1,
+			wantSkipped:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := listingServer(t, tt.lastPage, 2)
+			tt.config.Type = "url_parameter"
+			tt.config.Parameter = "page"
+			endpoint := models.Endpoint{
+				URL:                 server.URL + "/list",
+				Fetcher:             models.FetcherTypeHTTP,
+				MainElementSelector: "li.item",
+				PaginationConfig:    tt.config,
+			}
+
+			stats := &runStats{}
+			elements := 0
+			visited, err := visitListingPages(context.Background(), newHTTPFetcher(), endpoint, stats, func(doc Document, elems []Element) error {
+				elements += len(elems)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("visitListingPages() error: %v", err)
+			}
+			if visited != tt.wantVisited {
+				t.Errorf("visited = %d, want %d", visited, tt.wantVisited)
+			}
+			if elements != 2*tt.wantVisited {
+				t.Errorf("elements = %d, want %d", elements, 2*tt.wantVisited)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if got := stats.snapshot().PagesSkipped; got != tt.wantSkipped {
+				t.Errorf("skipped = %d, want %d", got, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+// nextButtonServer serves lastPage listing pages linked by a next button.
+// The last page shows the button disabled, or none at all when dropLast is
+// set.
+func nextButtonServer(t *testing.T, lastPage int, dropLast bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page < 1 || page > lastPage {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<html><body><ul>")
+		for i := 0; i < 2; i++ {
+			fmt.Fprintf(w, `<li class="item">%d-%d</li>`, page, i)
+		}
+		fmt.Fprint(w, "</ul>")
+		switch {
+		case page < lastPage:
+			fmt.Fprintf(w, `<a class="next" href="/list?page=%d">Next</a>`, page+1)
+		case !dropLast:
+			fmt.Fprint(w, `<a class="next disabled" aria-disabled="true">Next</a>`)
+		}
+		fmt.Fprint(w, "</body></html>")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVisitNextButtonPages(t *testing.T) {
+	browser := testBrowser(t)
+
+	tests := []struct {
+		name        string
+		dropLast    bool
+		config      models.PaginationConfig
+		wantVisited int
+	}{
+		{
+			name:        "stops at a disabled button",
+			config:      models.PaginationConfig{NextButtonSelector: "a.next"},
+			wantVisited: 3,
+		},
+		{
+			name:        "stops when the button is missing",
+			dropLast:    true,
+			config:      models.PaginationConfig{NextButtonSelector: "a.next"},
+			wantVisited: 3,
+		},
+		{
+			name:        "caps pages",
+			config:      models.PaginationConfig{NextButtonSelector: "a.next", MaxPages: 2},
+			wantVisited: 2,
+		},
+		{
+			name:        "xpath button",
+			config:      models.PaginationConfig{NextButtonSelector: `//a[text()="Next"]`, NextButtonSelectorLanguage: models.SelectorLanguageXPath},
+			wantVisited: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := nextButtonServer(t, 3, tt.dropLast)
+			tt.config.Type = string(models.PaginationConfigTypeNextButton)
+			endpoint := models.Endpoint{
+				URL:                 server.URL + "/list?page=1",
+				MainElementSelector: "li.item",
+				PaginationConfig:    tt.config,
+			}
+
+			var items []string
+			visited, err := visitListingPages(context.Background(), &pageFetcher{browser: browser}, endpoint, &runStats{}, func(doc Document, elems []Element) error {
+				for _, elem := range elems {
+					items = append(items, elem.Text())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("visitListingPages() error: %v", err)
+			}
+			if visited != tt.wantVisited {
+				t.Errorf("visited = %d, want %d", visited, tt.wantVisited)
+			}
+			if len(items) != 2*tt.wantVisited || items[len(items)-1] != fmt.Sprintf("%d-1", tt.wantVisited) {
+				t.Errorf("items = %v, want 2 items for each of the %d pages", items, tt.wantVisited)
+			}
+		})
+	}
+}
+
+// growingListingPage shows 5 items and adds 5 more up to 15, either when the
+// page is scrolled to the bottom or when the More button is clicked. The
+// button is disabled once all items are shown.
+const growingListingPage = `<html><body>
+<ul id="list"></ul>
+<button class="more">More</button>
+<script>
+	const list = document.getElementById("list")
+	const button = document.querySelector(".more")
+	let count = 0
+	const grow = () => {
+		if (count >= 15) return
+		for (let i = 0; i < 5; i++) {
+			const li = document.createElement("li")
+			li.className = "item"
+			li.style.height = "400px"
+			li.textContent = "item-" + count++
+			list.appendChild(li)
+		}
+		button.disabled = count >= 15
+	}
+	grow()
+	button.addEventListener("click", () => setTimeout(grow, 100))
+	if (location.search.includes("scroll")) {
+		window.addEventListener("scroll", () => {
+			if (window.innerHeight + window.scrollY >= document.body.scrollHeight - 10) setTimeout(grow, 100)
+		})
+	}
+</script>
+</body></html>`
+
+func TestVisitGrowingListing(t *testing.T) {
+	browser := testBrowser(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, growingListingPage)
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name      string
+		path      string
+		config    models.PaginationConfig
+		wantItems int
+	}{
+		{
+			name:      "infinite scroll stops at the target count",
+			path:      "/?scroll",
+			config:    models.PaginationConfig{Type: string(models.PaginationConfigTypeInfinite), TargetItemCount: 12},
+			wantItems: 12,
+		},
+		{
+			name:      "infinite scroll stops once the listing stops growing",
+			path:      "/?scroll",
+			config:    models.PaginationConfig{Type: string(models.PaginationConfigTypeInfinite)},
+			wantItems: 15,
+		},
+		{
+			name:      "load more stops at a disabled button",
+			path:      "/",
+			config:    models.PaginationConfig{Type: string(models.PaginationConfigTypeLoadMore), LoadMoreSelector: "button.more"},
+			wantItems: 15,
+		},
+		{
+			name:      "xpath load more button",
+			path:      "/",
+			config:    models.PaginationConfig{Type: string(models.PaginationConfigTypeLoadMore), LoadMoreSelector: `//button[text()="More"]`, LoadMoreSelectorLanguage: models.SelectorLanguageXPath},
+			wantItems: 15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := models.Endpoint{
+				URL:                 server.URL + tt.path,
+				MainElementSelector: "li.item",
+				PaginationConfig:    tt.config,
+			}
+
+			var items []string
+			_, err := visitListingPages(context.Background(), &pageFetcher{browser: browser}, endpoint, &runStats{}, func(doc Document, elems []Element) error {
+				for _, elem := range elems {
+					items = append(items, elem.Text())
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("visitListingPages() error: %v", err)
+			}
+			if len(items) != tt.wantItems {
+				t.Fatalf("got %d items, want %d: %v", len(items), tt.wantItems, items)
+			}
+			for i, item := range items {
+				if want := fmt.Sprintf("item-%d", i); item != want {
+					t.Errorf("item %d = %q, want %q", i, item, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// hostPoliteness is how hard a single host may be hit, shared by every
+// endpoint scraping it.
+type hostPoliteness struct {
+	// maxConcurrentLoads caps the pages and requests loading from the host
+	// at the same time. Pages that finished loading do not count, so an open
+	// listing page never blocks its own detail pages.
+	maxConcurrentLoads int
+	minDelay           time.Duration
+	requestsPerMinute  int
+}
+
+var (
+	politeness     hostPoliteness
+	politenessOnce sync.Once
+)
+
+// getPoliteness reads the limits from the environment once.
+func getPoliteness() hostPoliteness {
+	politenessOnce.Do(func() {
+		politeness = hostPoliteness{
+			maxConcurrentLoads: envInt("SCRAPE_HOST_MAX_CONCURRENT_PAGES", 2),
+			minDelay:           time.Duration(envInt("SCRAPE_HOST_MIN_DELAY_MS", 500)) * time.Millisecond,
+			requestsPerMinute:  envInt("SCRAPE_HOST_REQUESTS_PER_MINUTE", 60),
+		}
+		log.Printf("Host limits: %d concurrent pages, %s between requests, %d requests per minute",
+			politeness.maxConcurrentLoads, politeness.minDelay, politeness.requestsPerMinute)
+	})
+	return politeness
+}
+
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", name, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// hostLimiter tracks the loads and requests of one host.
+type hostLimiter struct {
+	slots chan struct{}
+
+	mu       sync.Mutex
+	last     time.Time
+	requests []time.Time
+}
+
+var (
+	hostLimitersMu sync.Mutex
+	hostLimiters   = map[string]*hostLimiter{}
+)
+
+func limiterFor(pageURL string) *hostLimiter {
+	host := pageDomain(pageURL)
+
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+	limiter, ok := hostLimiters[host]
+	if !ok {
+		var slots chan struct{}
+		if limit := getPoliteness().maxConcurrentLoads; limit > 0 {
+			slots = make(chan struct{}, limit)
+		}
+		limiter = &hostLimiter{slots: slots}
+		hostLimiters[host] = limiter
+	}
+	return limiter
+}
+
+// acquireLoad waits for a free load slot on the url's host. The returned
+// release func can be called more than once.
+func acquireLoad(ctx context.Context, pageURL string) (func(), error) {
+	limiter := limiterFor(pageURL)
+	if limiter.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case limiter.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a free page on %s: %w", pageDomain(pageURL), ctx.Err())
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-limiter.slots })
+	}, nil
+}
+
+// waitForRequest blocks until the url's host may receive another request,
+// keeping the minimum delay and the requests per minute budget.
+func waitForRequest(ctx context.Context, pageURL string) error {
+	limiter := limiterFor(pageURL)
+	limits := getPoliteness()
+
+	for {
+		wait := limiter.reserve(limits)
+		if wait <= 0 {
+			return nil
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("waiting to request %s: %w", pageDomain(pageURL), ctx.Err())
+		}
+	}
+}
+
+// reserve records a request and returns 0 when one may be sent now, or how
+// long to wait before trying again.
+func (l *hostLimiter) reserve(limits hostPoliteness) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Minute)
+	for len(l.requests) > 0 && l.requests[0].Before(cutoff) {
+		l.requests = l.requests[1:]
+	}
+
+	var wait time.Duration
+	if next := l.last.Add(limits.minDelay); next.After(now) {
+		wait = next.Sub(now)
+	}
+	if limits.requestsPerMinute > 0 && len(l.requests) >= limits.requestsPerMinute {
+		if budgetWait := l.requests[0].Add(time.Minute).Sub(now); budgetWait > wait {
+			wait = budgetWait
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	l.last = now
+	l.requests = append(l.requests, now)
+	return 0
+}
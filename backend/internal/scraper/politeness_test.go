@@ -0,0 +1,95 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Tests load their pages from local servers, the default delay between
+	// requests would only slow them down.
+	os.Setenv("SCRAPE_HOST_MIN_DELAY_MS", "0")
+	os.Setenv("SCRAPE_HOST_REQUESTS_PER_MINUTE", "0")
+	os.Exit(m.Run())
+}
+
+func TestHostLimiterReserve(t *testing.T) {
+	t.Run("min delay", func(t *testing.T) {
+		limiter := &hostLimiter{}
+		limits := hostPoliteness{minDelay: time.Minute}
+		if wait := limiter.reserve(limits); wait != 0 {
+			t.Fatalf("first reserve() = %s, want 0", wait)
+		}
+		if wait := limiter.reserve(limits); wait <= 59*time.Second || wait > time.Minute {
+			t.Errorf("second reserve() = %s, want about a minute", wait)
+		}
+		if got := len(limiter.requests); got != 1 {
+			t.Errorf("recorded requests = %d, want 1", got)
+		}
+	})
+
+	t.Run("requests per minute", func(t *testing.T) {
+		limiter := &hostLimiter{}
+		limits := hostPoliteness{requestsPerMinute: 2}
+		for i := 0; i < 2; i++ {
+			if wait := limiter.reserve(limits); wait != 0 {
+				t.Fatalf("reserve() %d = %s, want 0", i+1, wait)
+			}
+		}
+		if wait := limiter.reserve(limits); wait <= 59*time.Second || wait > time.Minute {
+			t.Errorf("reserve() over budget = %s, want about a minute", wait)
+		}
+	})
+
+	t.Run("requests older than a minute are forgotten", func(t *testing.T) {
+		old := time.Now().Add(-2 * time.Minute)
+		limiter := &hostLimiter{last: old, requests: []time.Time{old, old}}
+		if wait := limiter.reserve(hostPoliteness{minDelay: time.Second, requestsPerMinute: 2}); wait != 0 {
+			t.Errorf("reserve() = %s, want 0", wait)
+		}
+		if got := len(limiter.requests); got != 1 {
+			t.Errorf("recorded requests = %d, want 1", got)
+		}
+	})
+}
+
+func TestAcquireLoad(t *testing.T) {
+	const pageURL = "https://slots.test/list"
+	hostLimitersMu.Lock()
+	hostLimiters[pageDomain(pageURL)] = &hostLimiter{slots: make(chan struct{}, 1)}
+	hostLimitersMu.Unlock()
+	t.Cleanup(func() {
+		hostLimitersMu.Lock()
+		delete(hostLimiters, pageDomain(pageURL))
+		hostLimitersMu.Unlock()
+	})
+
+	release, err := acquireLoad(context.Background(), pageURL)
+	if err != nil {
+		t.Fatalf("acquireLoad() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := acquireLoad(ctx, pageURL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquireLoad() on a full host error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Releasing twice must not free a slot held by another load.
+	release()
+	release()
+	second, err := acquireLoad(context.Background(), pageURL)
+	if err != nil {
+		t.Fatalf("acquireLoad() after release error: %v", err)
+	}
+	defer second()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := acquireLoad(ctx, pageURL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquireLoad() after a double release error = %v, want context.DeadlineExceeded", err)
+	}
+}
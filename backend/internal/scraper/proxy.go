@@ -0,0 +1,356 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"scrapeit/internal/models"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	proxyBanDuration       = 15 * time.Minute
+	proxyUnhealthyDuration = 5 * time.Minute
+	proxyCheckInterval     = 5 * time.Minute
+	proxyCheckTimeout      = 10 * time.Second
+	defaultProxyCheckURL   = "https://www.gstatic.com/generate_204"
+)
+
+// BEGIN: proxy health
+
+// proxyState is what is known about a proxy across runs.
+type proxyState struct {
+	bannedUntil time.Time
+	lastChecked time.Time
+	lastError   string
+}
+
+var (
+	proxyStatesMu sync.Mutex
+	proxyStates   = map[string]*proxyState{}
+)
+
+func getProxyState(proxy models.Proxy) *proxyState {
+	key := proxy.Server()
+	state, ok := proxyStates[key]
+	if !ok {
+		state = &proxyState{}
+		proxyStates[key] = state
+	}
+	return state
+}
+
+// banProxy takes a proxy out of rotation for a while, e.g. after a target
+// blocked it.
+func banProxy(proxy models.Proxy, reason string) {
+	proxyStatesMu.Lock()
+	defer proxyStatesMu.Unlock()
+	state := getProxyState(proxy)
+	state.bannedUntil = time.Now().Add(proxyBanDuration)
+	state.lastError = reason
+	log.Printf("Banned proxy %s for %s: %s", proxy.Server(), proxyBanDuration, reason)
+}
+
+func isProxyBanned(proxy models.Proxy) bool {
+	proxyStatesMu.Lock()
+	defer proxyStatesMu.Unlock()
+	return time.Now().Before(getProxyState(proxy).bannedUntil)
+}
+
+// ProxyHealth is the result of a proxy health check.
+type ProxyHealth struct {
+	ProxyID     string    `json:"proxyId"`
+	Server      string    `json:"server"`
+	Healthy     bool      `json:"healthy"`
+	Error       string    `json:"error,omitempty"`
+	BannedUntil time.Time `json:"bannedUntil,omitempty"`
+}
+
+// CheckProxy requests the check URL through the proxy. A failing proxy is
+// taken out of rotation for a few minutes.
+func CheckProxy(proxy models.Proxy) ProxyHealth {
+	err := requestThroughProxy(proxy)
+
+	proxyStatesMu.Lock()
+	defer proxyStatesMu.Unlock()
+	state := getProxyState(proxy)
+	state.lastChecked = time.Now()
+	health := ProxyHealth{ProxyID: proxy.ID, Server: proxy.Server(), Healthy: err == nil}
+	if err != nil {
+		state.lastError = err.Error()
+		if until := time.Now().Add(proxyUnhealthyDuration); until.After(state.bannedUntil) {
+			state.bannedUntil = until
+		}
+		health.Error = err.Error()
+	}
+	if time.Now().Before(state.bannedUntil) {
+		health.Healthy = false
+		health.BannedUntil = state.bannedUntil
+		health.Error = state.lastError
+	}
+	return health
+}
+
+func requestThroughProxy(proxy models.Proxy) error {
+	proxyURL, err := url.Parse(proxy.Server())
+	if err != nil {
+		return fmt.Errorf("invalid proxy %s: %w", proxy.Server(), err)
+	}
+	if proxy.Username != "" {
+		proxyURL.User = url.UserPassword(proxy.Username, proxy.Password)
+	}
+
+	checkURL := os.Getenv("PROXY_CHECK_URL")
+	if checkURL == "" {
+		checkURL = defaultProxyCheckURL
+	}
+
+	client := &http.Client{
+		Timeout:   proxyCheckTimeout,
+		Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+	}
+	resp, err := client.Get(checkURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("check request returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// needsCheck reports whether the proxy was not health checked recently.
+func needsCheck(proxy models.Proxy) bool {
+	proxyStatesMu.Lock()
+	defer proxyStatesMu.Unlock()
+	return time.Since(getProxyState(proxy).lastChecked) > proxyCheckInterval
+}
+
+// isBlockStatus reports whether a response status means the target blocked
+// the proxy.
+func isBlockStatus(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// END: proxy health
+
+// BEGIN: proxy rotation
+
+// proxyRotator hands out the proxies of an endpoint's pool for one run
+// according to the endpoint's rotation.
+type proxyRotator struct {
+	mu       sync.Mutex
+	proxies  []models.Proxy
+	rotation models.ProxyRotation
+	next     int
+	current  *models.Proxy
+	byDomain map[string]models.Proxy
+}
+
+func newProxyRotator(config *models.EndpointProxyConfig) *proxyRotator {
+	if config == nil || config.PoolID == "" {
+		return nil
+	}
+	pool, err := loadProxyPool(config.PoolID)
+	if err != nil {
+		log.Printf("Error loading proxy pool %s: %v", config.PoolID, err)
+		return nil
+	}
+	rotation := config.Rotation
+	if rotation == "" {
+		rotation = models.ProxyRotationPerRun
+	}
+	return &proxyRotator{proxies: pool.Proxies, rotation: rotation, byDomain: map[string]models.Proxy{}}
+}
+
+func loadProxyPool(poolID string) (models.ProxyPool, error) {
+	var pool models.ProxyPool
+	id, err := primitive.ObjectIDFromHex(poolID)
+	if err != nil {
+		return pool, err
+	}
+	client, err := models.GetDbClient()
+	if err != nil {
+		return pool, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = client.Database("scrapeit").Collection("proxy_pools").FindOne(ctx, bson.M{"_id": id}).Decode(&pool)
+	return pool, err
+}
+
+// proxyFor returns the proxy to load pageURL with. A nil rotator means the
+// endpoint does not use a proxy.
+func (r *proxyRotator) proxyFor(pageURL string) (*models.Proxy, error) {
+	if r == nil {
+		return nil, nil
+	}
+	domain := pageDomain(pageURL)
+	if proxy := r.assigned(domain); proxy != nil {
+		return proxy, nil
+	}
+
+	// Health checks take up to proxyCheckTimeout per proxy, so r.mu is not
+	// held while picking.
+	proxy, err := r.pick()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch r.rotation {
+	case models.ProxyRotationPerPage:
+	case models.ProxyRotationStickyDomain:
+		// Another page of the domain may have picked a proxy meanwhile.
+		if current, ok := r.byDomain[domain]; ok && !isProxyBanned(current) {
+			return &current, nil
+		}
+		r.byDomain[domain] = *proxy
+	default:
+		if r.current != nil && !isProxyBanned(*r.current) {
+			return r.current, nil
+		}
+		r.current = proxy
+	}
+	return proxy, nil
+}
+
+// assigned returns the proxy the rotation still uses for domain, or nil when
+// a new one has to be picked.
+func (r *proxyRotator) assigned(domain string) *models.Proxy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.rotation {
+	case models.ProxyRotationPerPage:
+		return nil
+	case models.ProxyRotationStickyDomain:
+		if proxy, ok := r.byDomain[domain]; ok && !isProxyBanned(proxy) {
+			return &proxy
+		}
+		return nil
+	default:
+		if r.current != nil && !isProxyBanned(*r.current) {
+			return r.current
+		}
+		return nil
+	}
+}
+
+// pick returns the next usable proxy of the pool, health checking proxies
+// that were not checked recently. It only locks r.mu to advance the
+// rotation, not for the checks.
+func (r *proxyRotator) pick() (*models.Proxy, error) {
+	for range r.proxies {
+		r.mu.Lock()
+		proxy := r.proxies[r.next%len(r.proxies)]
+		r.next++
+		r.mu.Unlock()
+
+		if isProxyBanned(proxy) {
+			continue
+		}
+		if needsCheck(proxy) && !CheckProxy(proxy).Healthy {
+			continue
+		}
+		return &proxy, nil
+	}
+	return nil, fmt.Errorf("no usable proxy left in pool")
+}
+
+func pageDomain(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	return u.Hostname()
+}
+
+// END: proxy rotation
+
+// BEGIN: proxied pages
+
+// newProxyContext creates a browser context that sends its traffic through
+// proxy. Pages opened in it dispose the context in destroyPage.
+func newProxyContext(browser *rod.Browser, proxy models.Proxy) (*rod.Browser, error) {
+	if proxy.Protocol == models.ProxyProtocolSOCKS5 && proxy.Username != "" {
+		log.Printf("Browser pages can not authenticate to socks proxy %s, the credentials are ignored", proxy.Server())
+	}
+	res, err := proto.TargetCreateBrowserContext{ProxyServer: proxy.Server()}.Call(browser)
+	if err != nil {
+		return nil, fmt.Errorf("error creating browser context for proxy %s: %w", proxy.Server(), err)
+	}
+	contextBrowser := *browser
+	contextBrowser.BrowserContextID = res.BrowserContextID
+	return &contextBrowser, nil
+}
+
+// handleProxyAuth answers the proxy's authentication challenges on page with
+// the proxy credentials.
+func handleProxyAuth(page *rod.Page, proxy models.Proxy) error {
+	if proxy.Username == "" || proxy.Protocol == models.ProxyProtocolSOCKS5 {
+		return nil
+	}
+	if err := (proto.FetchEnable{HandleAuthRequests: true}).Call(page); err != nil {
+		return fmt.Errorf("error enabling proxy authentication: %w", err)
+	}
+	go page.EachEvent(func(e *proto.FetchRequestPaused) {
+		_ = proto.FetchContinueRequest{RequestID: e.RequestID}.Call(page)
+	}, func(e *proto.FetchAuthRequired) {
+		_ = proto.FetchContinueWithAuth{
+			RequestID: e.RequestID,
+			AuthChallengeResponse: &proto.FetchAuthChallengeResponse{
+				Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+				Username: proxy.Username,
+				Password: proxy.Password,
+			},
+		}.Call(page)
+	})()
+	return nil
+}
+
+// destroyPage closes page together with the browser context it was opened
+// in, unless that is the browser's default context.
+func destroyPage(page *rod.Page) error {
+	err := page.Close()
+	if contextID := page.Browser().BrowserContextID; contextID != "" {
+		if disposeErr := (proto.TargetDisposeBrowserContext{BrowserContextID: contextID}).Call(page.Browser()); disposeErr != nil {
+			log.Printf("Error disposing browser context %s: %v", contextID, disposeErr)
+		}
+	}
+	return err
+}
+
+// navigationStatus returns the HTTP status of the page's main document, or 0
+// when the browser does not report it.
+func navigationStatus(page *rod.Page) int {
+	res, err := page.Eval(`() => {
+		const entry = performance.getEntriesByType("navigation")[0]
+		return entry && entry.responseStatus ? entry.responseStatus : 0
+	}`)
+	if err != nil {
+		return 0
+	}
+	return res.Value.Int()
+}
+
+// warnProxyIgnored logs that a fetcher without a browser does not use the
+// endpoint's proxy pool.
+func warnProxyIgnored(endpoint models.Endpoint) {
+	if endpoint.Proxy != nil {
+		log.Printf("Ignoring proxy pool of endpoint %s, the %s fetcher does not run a browser", endpoint.ID, endpoint.Fetcher)
+	}
+}
+
+// END: proxied pages
@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"scrapeit/internal/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// checkedProxies returns n proxies that count as recently health checked, so
+// picking them does not send check requests.
+func checkedProxies(t *testing.T, n int) []models.Proxy {
+	t.Helper()
+	proxies := make([]models.Proxy, n)
+	for i := range proxies {
+		proxies[i] = models.Proxy{ID: strconv.Itoa(i + 1), Host: t.Name() + strconv.Itoa(i+1) + ".test", Port: 8080}
+	}
+	proxyStatesMu.Lock()
+	for _, proxy := range proxies {
+		getProxyState(proxy).lastChecked = time.Now()
+	}
+	proxyStatesMu.Unlock()
+	t.Cleanup(func() { forgetProxies(proxies) })
+	return proxies
+}
+
+func forgetProxies(proxies []models.Proxy) {
+	proxyStatesMu.Lock()
+	defer proxyStatesMu.Unlock()
+	for _, proxy := range proxies {
+		delete(proxyStates, proxy.Server())
+	}
+}
+
+func newTestRotator(proxies []models.Proxy, rotation models.ProxyRotation) *proxyRotator {
+	return &proxyRotator{proxies: proxies, rotation: rotation, byDomain: map[string]models.Proxy{}}
+}
+
+// proxyIDs loads the urls in order and returns the IDs of the proxies used.
+func proxyIDs(t *testing.T, rotator *proxyRotator, urls ...string) []string {
+	t.Helper()
+	ids := make([]string, len(urls))
+	for i, pageURL := range urls {
+		proxy, err := rotator.proxyFor(pageURL)
+		if err != nil {
+			t.Fatalf("proxyFor(%s) error: %v", pageURL, err)
+		}
+		ids[i] = proxy.ID
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("proxies = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("proxies = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestProxyRotation(t *testing.T) {
+	t.Run("per run", func(t *testing.T) {
+		proxies := checkedProxies(t, 3)
+		rotator := newTestRotator(proxies, models.ProxyRotationPerRun)
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/1", "https://b.test/1", "https://a.test/2"), "1", "1", "1")
+
+		banProxy(proxies[0], "blocked")
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/3", "https://b.test/2"), "2", "2")
+	})
+
+	t.Run("per page", func(t *testing.T) {
+		proxies := checkedProxies(t, 3)
+		rotator := newTestRotator(proxies, models.ProxyRotationPerPage)
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/1", "https://a.test/2", "https://a.test/3", "https://a.test/4"), "1", "2", "3", "1")
+
+		banProxy(proxies[1], "blocked")
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/5", "https://a.test/6"), "3", "1")
+	})
+
+	t.Run("sticky domain", func(t *testing.T) {
+		proxies := checkedProxies(t, 3)
+		rotator := newTestRotator(proxies, models.ProxyRotationStickyDomain)
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/1", "https://b.test/1", "https://a.test/2", "https://b.test/2"), "1", "2", "1", "2")
+
+		banProxy(proxies[0], "blocked")
+		assertIDs(t, proxyIDs(t, rotator, "https://a.test/3", "https://b.test/3", "https://a.test/4"), "3", "2", "3")
+	})
+
+	t.Run("every proxy banned", func(t *testing.T) {
+		proxies := checkedProxies(t, 2)
+		for _, proxy := range proxies {
+			banProxy(proxy, "blocked")
+		}
+		if _, err := newTestRotator(proxies, models.ProxyRotationPerPage).proxyFor("https://a.test"); err == nil {
+			t.Error("proxyFor() error = nil, want no usable proxy")
+		}
+	})
+
+	t.Run("no pool", func(t *testing.T) {
+		var rotator *proxyRotator
+		if proxy, err := rotator.proxyFor("https://a.test"); proxy != nil || err != nil {
+			t.Errorf("proxyFor() = %v, %v, want no proxy", proxy, err)
+		}
+	})
+}
+
+// testProxy starts an HTTP proxy that answers every request with status.
+func testProxy(t *testing.T, status int) models.Proxy {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return proxyAt(t, server.URL)
+}
+
+func proxyAt(t *testing.T, rawURL string) models.Proxy {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(u.Host)
+	portNumber, _ := strconv.Atoi(port)
+	proxy := models.Proxy{ID: rawURL, Host: host, Port: portNumber}
+	t.Cleanup(func() { forgetProxies([]models.Proxy{proxy}) })
+	return proxy
+}
+
+func TestProxyHealthChecks(t *testing.T) {
+	t.Setenv("PROXY_CHECK_URL", "http://check.test/generate_204")
+
+	healthy := testProxy(t, http.StatusNoContent)
+	rejecting := testProxy(t, http.StatusProxyAuthRequired)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	down := proxyAt(t, closed.URL)
+	closed.Close()
+
+	if health := CheckProxy(healthy); !health.Healthy {
+		t.Errorf("CheckProxy(healthy) = %+v, want healthy", health)
+	}
+	if health := CheckProxy(rejecting); health.Healthy || health.BannedUntil.IsZero() {
+		t.Errorf("CheckProxy(rejecting) = %+v, want unhealthy until a later check", health)
+	}
+
+	rotator := newTestRotator([]models.Proxy{down, healthy}, models.ProxyRotationPerPage)
+	assertIDs(t, proxyIDs(t, rotator, "https://a.test/1", "https://a.test/2"), healthy.ID, healthy.ID)
+	if !isProxyBanned(down) {
+		t.Error("proxy failing its check is not taken out of rotation")
+	}
+}
@@ -0,0 +1,139 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"scrapeit/internal/models"
+	"slices"
+	"time"
+)
+
+// ErrElementNotFound is wrapped by fetch errors when the element waited for
+// did not show up on the page.
+var ErrElementNotFound = errors.New("element not found")
+
+// HTTPStatusError is returned when a page answered with a status that means
+// its content was not served.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d for %s", e.StatusCode, e.URL)
+}
+
+// retryClass returns the class of a retryable error, or "" when retrying
+// would not help.
+func retryClass(err error) models.RetryErrorClass {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return models.RetryErrorTooManyRequests
+		case http.StatusServiceUnavailable:
+			return models.RetryErrorUnavailable
+		}
+		return ""
+	}
+	// A missing element is usually a timeout as well, it is checked first
+	// so it gets its own class.
+	if errors.Is(err, ErrElementNotFound) {
+		return models.RetryErrorElementNotFound
+	}
+	var pageErr *PageError
+	if isTimeout(err) || (errors.As(err, &pageErr) && pageErr.Kind == PageErrorTimeout) {
+		return models.RetryErrorTimeout
+	}
+	return ""
+}
+
+// retryPolicyWithDefaults fills in the unset values of the policy.
+func retryPolicyWithDefaults(policy models.RetryPolicy) models.RetryPolicy {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoffMs <= 0 {
+		policy.InitialBackoffMs = 1000
+	}
+	if policy.MaxBackoffMs <= 0 {
+		policy.MaxBackoffMs = 30000
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	if policy.Jitter <= 0 || policy.Jitter > 1 {
+		policy.Jitter = 0.2
+	}
+	return policy
+}
+
+// retryBackoff is how long to wait after the given failed attempt, starting
+// at 1.
+func retryBackoff(policy models.RetryPolicy, attempt int) time.Duration {
+	backoff := float64(policy.InitialBackoffMs) * math.Pow(policy.Multiplier, float64(attempt-1))
+	backoff = math.Min(backoff, float64(policy.MaxBackoffMs))
+	backoff += backoff * policy.Jitter * (2*rand.Float64() - 1)
+	return time.Duration(backoff) * time.Millisecond
+}
+
+// BEGIN: retryFetcher
+
+// retryFetcher loads a page again when it failed with an error the policy
+// retries. Every page and detail item goes through Fetch, so each of them
+// gets its own attempts.
+type retryFetcher struct {
+	fetcher Fetcher
+	policy  models.RetryPolicy
+	stats   *runStats
+}
+
+// withRetries wraps the fetcher when the endpoint has a retry policy.
+func withRetries(fetcher Fetcher, endpoint models.Endpoint, stats *runStats) Fetcher {
+	if endpoint.Retry == nil {
+		return fetcher
+	}
+	policy := retryPolicyWithDefaults(*endpoint.Retry)
+	if policy.MaxAttempts <= 1 {
+		return fetcher
+	}
+	return &retryFetcher{fetcher: fetcher, policy: policy, stats: stats}
+}
+
+func (f *retryFetcher) retries(class models.RetryErrorClass) bool {
+	return class != "" && (len(f.policy.RetryOn) == 0 || slices.Contains(f.policy.RetryOn, class))
+}
+
+func (f *retryFetcher) Fetch(ctx context.Context, url string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	for attempt := 1; ; attempt++ {
+		doc, err := f.fetcher.Fetch(ctx, url, elementToWaitFor, actions)
+		if err == nil {
+			return doc, nil
+		}
+
+		class := retryClass(err)
+		if ctx.Err() != nil || !f.retries(class) {
+			return nil, err
+		}
+		if attempt >= f.policy.MaxAttempts {
+			f.stats.recordRetriesExhausted()
+			return nil, fmt.Errorf("giving up on %s after %d attempts: %w", url, attempt, err)
+		}
+
+		f.stats.recordRetry(class)
+		backoff := retryBackoff(f.policy, attempt)
+		log.Printf("Attempt %d of %d for %s failed (%s), retrying in %s: %v", attempt, f.policy.MaxAttempts, url, class, backoff.Round(time.Millisecond), err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, err
+		}
+	}
+}
+
+// END: retryFetcher
@@ -0,0 +1,132 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"scrapeit/internal/models"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
+
+func TestRetryClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want models.RetryErrorClass
+	}{
+		{"too many requests", &HTTPStatusError{URL: "u", StatusCode: 429}, models.RetryErrorTooManyRequests},
+		{"unavailable", &HTTPStatusError{URL: "u", StatusCode: 503}, models.RetryErrorUnavailable},
+		{"wrapped status", fmt.Errorf("loading: %w", &HTTPStatusError{URL: "u", StatusCode: 503}), models.RetryErrorUnavailable},
+		{"not found status", &HTTPStatusError{URL: "u", StatusCode: 404}, ""},
+		{"forbidden status", &HTTPStatusError{URL: "u", StatusCode: 403}, ""},
+		{"element not found", fmt.Errorf("waiting for .item: %w", ErrElementNotFound), models.RetryErrorElementNotFound},
+		{"element not found after timeout", fmt.Errorf("%w: %w", ErrElementNotFound, context.DeadlineExceeded), models.RetryErrorElementNotFound},
+		{"deadline exceeded", context.DeadlineExceeded, models.RetryErrorTimeout},
+		{"network timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, models.RetryErrorTimeout},
+		{"page timeout", &PageError{Kind: PageErrorTimeout, URL: "u", Err: errors.New("solver gave up")}, models.RetryErrorTimeout},
+		{"page blocked", &PageError{Kind: PageErrorBlocked, URL: "u", Err: errors.New("captcha")}, ""},
+		{"cancelled", context.Canceled, ""},
+		{"robots.txt", fmt.Errorf("%w: u", ErrDisallowedByRobotsTxt), ""},
+		{"other error", errors.New("boom"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryClass(tt.err); got != tt.want {
+				t.Errorf("retryClass(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyWithDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy models.RetryPolicy
+		want   models.RetryPolicy
+	}{
+		{
+			name:   "empty",
+			policy: models.RetryPolicy{},
+			want:   models.RetryPolicy{MaxAttempts: 3, InitialBackoffMs: 1000, MaxBackoffMs: 30000, Multiplier: 2, Jitter: 0.2},
+		},
+		{
+			name:   "set values are kept",
+			policy: models.RetryPolicy{MaxAttempts: 5, InitialBackoffMs: 200, MaxBackoffMs: 1000, Multiplier: 1.5, Jitter: 0.5},
+			want:   models.RetryPolicy{MaxAttempts: 5, InitialBackoffMs: 200, MaxBackoffMs: 1000, Multiplier: 1.5, Jitter: 0.5},
+		},
+		{
+			name:   "invalid values",
+			policy: models.RetryPolicy{MaxAttempts: -1, Multiplier: 0.5, Jitter: 2},
+			want:   models.RetryPolicy{MaxAttempts: 3, InitialBackoffMs: 1000, MaxBackoffMs: 30000, Multiplier: 2, Jitter: 0.2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryPolicyWithDefaults(tt.policy); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("retryPolicyWithDefaults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := models.RetryPolicy{InitialBackoffMs: 500, MaxBackoffMs: 5000, Multiplier: 2}
+
+	tests := []struct {
+		name    string
+		policy  models.RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt", policy, 1, 500 * time.Millisecond},
+		{"second attempt", policy, 2, time.Second},
+		{"third attempt", policy, 3, 2 * time.Second},
+		{"capped", policy, 5, 5 * time.Second},
+		{"far beyond the cap", policy, 40, 5 * time.Second},
+		{"constant", models.RetryPolicy{InitialBackoffMs: 300, MaxBackoffMs: 5000, Multiplier: 1}, 4, 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryBackoff(tt.policy, tt.attempt); got != tt.want {
+				t.Errorf("retryBackoff(attempt %d) = %s, want %s", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffJitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"first attempt", 1, 750 * time.Millisecond, 1250 * time.Millisecond},
+		{"capped", 10, 3 * time.Second, 5 * time.Second},
+	}
+
+	policy := models.RetryPolicy{InitialBackoffMs: 1000, MaxBackoffMs: 4000, Multiplier: 2, Jitter: 0.25}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if got := retryBackoff(policy, tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("retryBackoff(attempt %d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
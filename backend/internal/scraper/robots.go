@@ -0,0 +1,359 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	robotsTxtCacheDuration = 24 * time.Hour
+	// robotsTxtFailureCacheDuration is how long a robots.txt that could not
+	// be fetched is treated as allowing everything before it is retried.
+	robotsTxtFailureCacheDuration = 10 * time.Minute
+	robotsTxtMaxSize              = 512 * 1024
+	// robotsUserAgent is the product token rules are looked up for. Sites
+	// without a group for it fall back to the * group.
+	robotsUserAgent = "scrapeit"
+)
+
+// ErrDisallowedByRobotsTxt is returned when a group respecting robots.txt
+// tries to load a disallowed URL.
+var ErrDisallowedByRobotsTxt = errors.New("disallowed by robots.txt")
+
+// BEGIN: robots.txt parsing
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsRules are the rules of the robots.txt group that applies to us.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobotsTxt reads the rules of the group for userAgent, or of the *
+// group when no group names it.
+func parseRobotsTxt(r io.Reader, userAgent string) robotsRules {
+	type group struct {
+		agents []string
+		rules  robotsRules
+	}
+	var groups []*group
+	var current *group
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group.
+			if current == nil || !lastWasAgent {
+				current = &group{}
+				groups = append(groups, current)
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+			continue
+		case "allow", "disallow":
+			// An empty disallow allows everything and adds no rule.
+			if current != nil && value != "" {
+				current.rules.rules = append(current.rules.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if current != nil {
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					current.rules.crawlDelay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+		lastWasAgent = false
+	}
+
+	var wildcard *robotsRules
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == userAgent {
+				return g.rules
+			}
+			if agent == "*" && wildcard == nil {
+				wildcard = &g.rules
+			}
+		}
+	}
+	if wildcard != nil {
+		return *wildcard
+	}
+	return robotsRules{}
+}
+
+// allowed reports whether path (with its query) may be crawled. The longest
+// matching rule wins, allow wins a tie.
+func (r robotsRules) allowed(path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !robotsPatternMatches(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			longest = len(rule.pattern)
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsPatternMatches matches a robots.txt path pattern, supporting the *
+// wildcard and the $ end anchor.
+func robotsPatternMatches(pattern string, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expression := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expression += "$"
+	}
+	matched, err := regexp.MatchString(expression, path)
+	return err == nil && matched
+}
+
+// END: robots.txt parsing
+
+// BEGIN: robots.txt cache
+
+type cachedRobotsTxt struct {
+	rules     robotsRules
+	fetchedAt time.Time
+	failed    bool
+}
+
+func (c cachedRobotsTxt) fresh() bool {
+	if c.failed {
+		return time.Since(c.fetchedAt) < robotsTxtFailureCacheDuration
+	}
+	return time.Since(c.fetchedAt) < robotsTxtCacheDuration
+}
+
+var (
+	robotsTxtMu    sync.Mutex
+	robotsTxtCache = map[string]cachedRobotsTxt{}
+)
+
+// getRobotsRules returns the cached rules for the url's host, fetching
+// robots.txt when it is not cached or the cache is stale.
+func getRobotsRules(ctx context.Context, pageURL string) robotsRules {
+	host := helpers.GetBaseURL(pageURL)
+
+	robotsTxtMu.Lock()
+	cached, ok := robotsTxtCache[host]
+	robotsTxtMu.Unlock()
+	if ok && cached.fresh() {
+		return cached.rules
+	}
+
+	rules, err := fetchRobotsTxt(ctx, host)
+	failed := err != nil
+	if failed {
+		// An unreachable robots.txt is treated as allowing everything until
+		// it is fetched again.
+		log.Printf("Error fetching robots.txt of %s, allowing everything for %s: %v", host, robotsTxtFailureCacheDuration, err)
+		rules = robotsRules{}
+	}
+
+	robotsTxtMu.Lock()
+	robotsTxtCache[host] = cachedRobotsTxt{rules: rules, fetchedAt: time.Now(), failed: failed}
+	robotsTxtMu.Unlock()
+	return rules
+}
+
+func fetchRobotsTxt(ctx context.Context, host string) (robotsRules, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, host+"/robots.txt", nil)
+	if err != nil {
+		return robotsRules{}, err
+	}
+	req.Header.Set("User-Agent", httpFetcherUserAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return robotsRules{}, err
+	}
+	defer resp.Body.Close()
+
+	// A missing robots.txt allows everything.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return robotsRules{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return robotsRules{}, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return parseRobotsTxt(io.LimitReader(resp.Body, robotsTxtMaxSize), robotsUserAgent), nil
+}
+
+// RobotsTxtAllows reports whether the host's robots.txt allows pageURL.
+func RobotsTxtAllows(ctx context.Context, pageURL string) bool {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return true
+	}
+	return getRobotsRules(ctx, pageURL).allowed(u.RequestURI())
+}
+
+// RobotsTxtWarnings lists the endpoint's configured URLs that robots.txt
+// disallows.
+func RobotsTxtWarnings(ctx context.Context, endpoint models.Endpoint) []string {
+	urls := []string{endpoint.URL}
+	config := endpoint.PaginationConfig
+	switch config.Type {
+	case "url_parameter":
+		urls = append(urls, buildPaginationURL(endpoint.URL, config, config.Start))
+	case "url_path":
+		if config.UrlRegexToInsert != nil {
+			urls = append(urls, buildPaginationURL(endpoint.URL, config, config.Start))
+		}
+	}
+
+	warnings := []string{}
+	seen := map[string]bool{}
+	for _, pageURL := range urls {
+		if seen[pageURL] {
+			continue
+		}
+		seen[pageURL] = true
+		if !RobotsTxtAllows(ctx, pageURL) {
+			warnings = append(warnings, fmt.Sprintf("%s is disallowed by robots.txt", pageURL))
+		}
+	}
+	return warnings
+}
+
+// END: robots.txt cache
+
+// BEGIN: robotsFetcher
+
+// robotsFetcher refuses URLs the host's robots.txt disallows and keeps its
+// Crawl-delay between loads.
+type robotsFetcher struct {
+	fetcher Fetcher
+}
+
+// withRobotsTxt wraps the fetcher when the group opted into robots.txt
+// compliance.
+func withRobotsTxt(fetcher Fetcher, group models.ScrapeGroup) Fetcher {
+	if !group.RespectRobotsTxt {
+		return fetcher
+	}
+	return &robotsFetcher{fetcher: fetcher}
+}
+
+var (
+	crawlDelayMu   sync.Mutex
+	crawlDelayNext = map[string]time.Time{}
+)
+
+func (f *robotsFetcher) Fetch(ctx context.Context, pageURL string, elementToWaitFor Selector, actions []models.PageAction) (Document, error) {
+	if err := checkRobotsTxt(ctx, pageURL); err != nil {
+		return nil, err
+	}
+	return f.fetcher.Fetch(ctx, pageURL, elementToWaitFor, actions)
+}
+
+// checkRobotsTxt refuses pageURL when the host's robots.txt disallows it and
+// otherwise waits for the host's Crawl-delay.
+func checkRobotsTxt(ctx context.Context, pageURL string) error {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %w", pageURL, err)
+	}
+	rules := getRobotsRules(ctx, pageURL)
+	if !rules.allowed(u.RequestURI()) {
+		log.Printf("Refusing %s, it is disallowed by robots.txt", pageURL)
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobotsTxt, pageURL)
+	}
+	return waitForCrawlDelay(ctx, u.Host, rules.crawlDelay)
+}
+
+// respectsRobotsTxt reports whether fetcher, or a fetcher it wraps, is a
+// robotsFetcher.
+func respectsRobotsTxt(fetcher Fetcher) bool {
+	switch f := fetcher.(type) {
+	case *robotsFetcher:
+		return true
+	case *retryFetcher:
+		return respectsRobotsTxt(f.fetcher)
+	default:
+		return false
+	}
+}
+
+// waitForPageStep is called before a click or scroll loads more of an open
+// page. Those requests do not go through Fetch, so the step keeps the host's
+// politeness limits here and, when the fetcher respects robots.txt, its rules
+// and Crawl-delay for the page's current URL.
+func waitForPageStep(ctx context.Context, fetcher Fetcher, doc Document) error {
+	pageURL := doc.URL()
+	if err := waitForRequest(ctx, pageURL); err != nil {
+		return err
+	}
+	if !respectsRobotsTxt(fetcher) {
+		return nil
+	}
+	return checkRobotsTxt(ctx, pageURL)
+}
+
+// waitForCrawlDelay spaces the loads of a host by its Crawl-delay.
+func waitForCrawlDelay(ctx context.Context, host string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	crawlDelayMu.Lock()
+	start := time.Now()
+	if next := crawlDelayNext[host]; next.After(start) {
+		start = next
+	}
+	crawlDelayNext[host] = start.Add(delay)
+	crawlDelayMu.Unlock()
+
+	select {
+	case <-time.After(time.Until(start)):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// END: robotsFetcher
@@ -0,0 +1,140 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRobotsPatternMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"prefix", "/private", "/private/page", true},
+		{"prefix mismatch", "/private", "/public", false},
+		{"root", "/", "/anything", true},
+		{"wildcard", "/*.pdf", "/docs/file.pdf", true},
+		{"wildcard mismatch", "/*.pdf", "/docs/file.html", false},
+		{"wildcard in the middle", "/shop/*/reviews", "/shop/42/reviews?page=2", true},
+		{"anchored", "/*.pdf$", "/file.pdf", true},
+		{"anchored with query", "/*.pdf$", "/file.pdf?download=1", false},
+		{"special characters", "/search?q=", "/search?q=shoes", true},
+		{"dot is literal", "/a.b", "/axb", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := robotsPatternMatches(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("robotsPatternMatches(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRobotsRulesAllowed(t *testing.T) {
+	rules := robotsRules{rules: []robotsRule{
+		{allow: false, pattern: "/shop/"},
+		{allow: true, pattern: "/shop/public/"},
+		{allow: false, pattern: "/*.json$"},
+		{allow: false, pattern: "/tie"},
+		{allow: true, pattern: "/tie"},
+	}}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"no matching rule", "/about", true},
+		{"disallowed", "/shop/cart", false},
+		{"longer allow wins", "/shop/public/list", true},
+		{"anchored disallow", "/api/items.json", false},
+		{"anchored disallow with query", "/api/items.json?page=2", true},
+		{"allow wins a tie", "/tie", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.allowed(tt.path); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRobotsTxt(t *testing.T) {
+	tests := []struct {
+		name           string
+		robotsTxt      string
+		wantDelay      time.Duration
+		allowedPath    string
+		disallowedPath string
+	}{
+		{
+			name:           "wildcard group",
+			robotsTxt:      "User-agent: *\nDisallow: /private\nCrawl-delay: 2",
+			wantDelay:      2 * time.Second,
+			allowedPath:    "/public",
+			disallowedPath: "/private/1",
+		},
+		{
+			name: "own group wins over wildcard",
+			robotsTxt: "User-agent: *\nDisallow: /\n\n" +
+				"User-agent: ScrapeIt\nDisallow: /admin\nCrawl-delay: 0.5",
+			wantDelay:      500 * time.Millisecond,
+			allowedPath:    "/listing",
+			disallowedPath: "/admin",
+		},
+		{
+			name:           "consecutive user agents share a group",
+			robotsTxt:      "User-agent: otherbot\nUser-agent: scrapeit\nDisallow: /tmp # scratch files",
+			allowedPath:    "/",
+			disallowedPath: "/tmp/a",
+		},
+		{
+			name:        "other agents only",
+			robotsTxt:   "User-agent: otherbot\nDisallow: /\nCrawl-delay: 10",
+			allowedPath: "/anything",
+		},
+		{
+			name:        "empty disallow allows everything",
+			robotsTxt:   "User-agent: *\nDisallow:",
+			allowedPath: "/anything",
+		},
+		{
+			name:           "invalid crawl delay",
+			robotsTxt:      "User-agent: *\nCrawl-delay: soon\nDisallow: /x",
+			allowedPath:    "/y",
+			disallowedPath: "/x",
+		},
+		{
+			name:           "negative crawl delay",
+			robotsTxt:      "User-agent: *\nCrawl-delay: -3\nDisallow: /x",
+			allowedPath:    "/y",
+			disallowedPath: "/x",
+		},
+		{
+			name:        "rules before any user agent",
+			robotsTxt:   "Disallow: /\nCrawl-delay: 5\nUser-agent: *\nAllow: /",
+			allowedPath: "/page",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := parseRobotsTxt(strings.NewReader(tt.robotsTxt), robotsUserAgent)
+			if rules.crawlDelay != tt.wantDelay {
+				t.Errorf("crawlDelay = %s, want %s", rules.crawlDelay, tt.wantDelay)
+			}
+			if tt.allowedPath != "" && !rules.allowed(tt.allowedPath) {
+				t.Errorf("allowed(%q) = false, want true", tt.allowedPath)
+			}
+			if tt.disallowedPath != "" && rules.allowed(tt.disallowedPath) {
+				t.Errorf("allowed(%q) = true, want false", tt.disallowedPath)
+			}
+		})
+	}
+}
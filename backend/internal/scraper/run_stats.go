@@ -0,0 +1,89 @@
+package scraper
+
+import (
+	"scrapeit/internal/models"
+	"sync"
+)
+
+// runStats collects the statistics of a scrape run. Detail pages are scraped
+// concurrently, so updates are guarded by a mutex. A nil *runStats ignores
+// all updates, which is what test runs use.
+type runStats struct {
+	mu    sync.Mutex
+	stats models.ScrapeRunStats
+}
+
+func (r *runStats) setPagesVisited(pagesVisited int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.PagesVisited = pagesVisited
+}
+
+func (r *runStats) recordPageSkipped() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.PagesSkipped++
+}
+
+func (r *runStats) setRunID(runID string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.RunID = runID
+}
+
+func (r *runStats) setCancelled() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Cancelled = true
+}
+
+func (r *runStats) recordFallbackMatch(fieldID string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats.FallbackMatches == nil {
+		r.stats.FallbackMatches = map[string]int{}
+	}
+	r.stats.FallbackMatches[fieldID]++
+}
+
+func (r *runStats) recordRetry(class models.RetryErrorClass) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats.Retries == nil {
+		r.stats.Retries = map[models.RetryErrorClass]int{}
+	}
+	r.stats.Retries[class]++
+}
+
+func (r *runStats) recordRetriesExhausted() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.RetriesExhausted++
+}
+
+func (r *runStats) snapshot() models.ScrapeRunStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type scrapeRun struct {
+	cancel context.CancelFunc
+}
+
+// runningScrapes holds the endpoint runs in progress, keyed by group and
+// endpoint.
+var runningScrapes = struct {
+	mu   sync.Mutex
+	runs map[string]*scrapeRun
+}{runs: map[string]*scrapeRun{}}
+
+func runKey(groupId, endpointId string) string {
+	return groupId + "/" + endpointId
+}
+
+// runInfo identifies the run a page is loaded for, so failures can be
+// traced back to it.
+type runInfo struct {
+	groupId    string
+	endpointId string
+	runId      string
+}
+
+type runInfoKey struct{}
+
+func runInfoFrom(ctx context.Context) runInfo {
+	info, _ := ctx.Value(runInfoKey{}).(runInfo)
+	return info
+}
+
+// startRun registers an endpoint run and returns its context, which
+// CancelRun cancels and which carries the run's ID, and the func ending the
+// run.
+func startRun(ctx context.Context, groupId, endpointId string) (context.Context, func()) {
+	ctx = context.WithValue(ctx, runInfoKey{}, runInfo{groupId: groupId, endpointId: endpointId, runId: uuid.NewString()})
+	ctx, cancel := context.WithCancel(ctx)
+	key := runKey(groupId, endpointId)
+	run := &scrapeRun{cancel: cancel}
+
+	runningScrapes.mu.Lock()
+	runningScrapes.runs[key] = run
+	runningScrapes.mu.Unlock()
+
+	return ctx, func() {
+		runningScrapes.mu.Lock()
+		// A newer run of the endpoint may have replaced this one.
+		if runningScrapes.runs[key] == run {
+			delete(runningScrapes.runs, key)
+		}
+		runningScrapes.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelRun cancels the endpoint's run and reports whether one was running.
+// The run stops loading pages and returns the results it already scraped.
+func CancelRun(groupId, endpointId string) bool {
+	key := runKey(groupId, endpointId)
+
+	runningScrapes.mu.Lock()
+	run, ok := runningScrapes.runs[key]
+	delete(runningScrapes.runs, key)
+	runningScrapes.mu.Unlock()
+
+	if ok {
+		run.cancel()
+	}
+	return ok
+}
@@ -1,15 +1,12 @@
 package scraper
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
-	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
 	"sync"
 	"time"
 
@@ -43,94 +40,127 @@ func GetBrowser() *rod.Browser {
 }
 
 func GetStealthPage(ctx context.Context, browser *rod.Browser, url string, elementToWaitFor string) (*rod.Page, error) {
-	// Load the cookie store
-	store, err := LoadCookieStore()
+	return getStealthPage(ctx, browser, url, CSS(elementToWaitFor), pageOptions{})
+}
+
+// pageOptions configures what getStealthPage does on a page besides loading
+// it.
+type pageOptions struct {
+	actions []models.PageAction
+	login   *models.LoginConfig
+	proxy   *models.Proxy
+	// solver solves challenges shown instead of the page, FlareSolverr when
+	// it is nil.
+	solver ChallengeSolver
+	// solverOptional keeps the page when its challenge could not be solved.
+	solverOptional bool
+}
+
+func getStealthPage(ctx context.Context, browser *rod.Browser, url string, elementToWaitFor Selector, opts pageOptions) (*rod.Page, error) {
+	page, err := tabs.acquire(ctx, browser, opts.proxy)
 	if err != nil {
-		log.Fatalf("Failed to load cookie store: %v", err)
 		return nil, err
 	}
-
-	// Check if we have valid cookies
-	baseURL := helpers.GetBaseURL(url)
-	cookies, valid := GetValidCookies(store, baseURL)
-	// Parse the JSON response
-	var response struct {
-		Status   string `json:"status"`
-		Message  string `json:"message"`
-		Solution struct {
-			URL       string            `json:"url"`
-			Status    int               `json:"status"`
-			Headers   map[string]string `json:"headers"`
-			Response  string            `json:"response"`
-			Cookies   []Cookie          `json:"cookies"`
-			UserAgent string            `json:"userAgent"`
-		} `json:"solution"`
+	if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{Width: 1920, Height: 1080, DeviceScaleFactor: 2.0}); err != nil {
+		closePage(page)
+		return nil, fmt.Errorf("error setting viewport: %w", err)
 	}
 
-	if !valid {
-		flaresolverrURL := os.Getenv("FLARESOLVER_URL")
-		if flaresolverrURL == "" {
-			log.Fatalf("FLARESOLVER_URL is not set")
-			return nil, fmt.Errorf("FLARESOLVER_URL is not set")
-		}
-		// Make the request to get cookies if not valid
-		resp, err := http.Post(fmt.Sprintf("%s/v1", flaresolverrURL), "application/json", bytes.NewBuffer([]byte(fmt.Sprintf(`{
-			"cmd": "request.get",
-			"url": "%s",
-			"maxTimeout": 30000,
-			"returnOnlyCookies": true
-		}`, url))))
-		if err != nil {
-			log.Fatalf("Failed to make request: %v", err)
-			return nil, err
+	// Cookies that passed the site's challenge before usually still do.
+	applyChallengeCookies(page, cachedChallengeCookies(ctx, challengeCookieKey(url, opts.proxy)))
+
+	if opts.login != nil {
+		if err := ensureSession(ctx, page, *opts.login, false); err != nil {
+			return nil, failPage(ctx, page, url, err)
 		}
-		defer resp.Body.Close()
+	}
 
-		// Read the response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Fatalf("Failed to read response body: %v", err)
-			return nil, err
+	if err := loadPage(ctx, page, url, elementToWaitFor, opts); err != nil {
+		return nil, failPage(ctx, page, url, err)
+	}
+
+	if opts.proxy != nil {
+		if status := navigationStatus(page); isBlockStatus(status) {
+			banProxy(*opts.proxy, fmt.Sprintf("status %d from %s", status, url))
+			return nil, failPage(ctx, page, url, &PageError{Kind: PageErrorBlocked, URL: url, Err: fmt.Errorf("proxy %s blocked: %w", opts.proxy.Server(), &HTTPStatusError{URL: url, StatusCode: status})})
 		}
+	}
 
-		if err := json.Unmarshal(body, &response); err != nil {
-			log.Fatalf("Failed to unmarshal JSON response: %v", err)
-			return nil, err
+	// A lost session is only noticed on the page, so the login runs again
+	// and the page is reloaded once.
+	if opts.login != nil && !isLoggedIn(ctx, page, *opts.login) {
+		if err := ensureSession(ctx, page, *opts.login, true); err != nil {
+			return nil, failPage(ctx, page, url, err)
+		}
+		if err := loadPage(ctx, page, url, elementToWaitFor, opts); err != nil {
+			return nil, failPage(ctx, page, url, err)
 		}
+		if !isLoggedIn(ctx, page, *opts.login) {
+			return nil, failPage(ctx, page, url, fmt.Errorf("not logged in on %s after logging in again", url))
+		}
+	}
 
-		cookies = UserAgentWithCookies{
-			Cookie:      response.Solution.Cookies,
-			UserAgent:   response.Solution.UserAgent,
-			LastUpdated: time.Now(),
+	if err := runPageActions(ctx, page, opts.actions); err != nil {
+		log.Printf("Error running page actions on %s: %v", url, err)
+		return nil, failPage(ctx, page, url, err)
+	}
+
+	elementCtx, elementCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer elementCancel()
+
+	_, err = pageElement(page.Context(elementCtx), elementToWaitFor)
+	if err != nil {
+		if err == context.DeadlineExceeded {
+			log.Printf("Timeout reached while waiting for element %s: %v", elementToWaitFor, err)
+		} else {
+			log.Printf("Error finding element %s: %v", elementToWaitFor, err)
+		}
+		if ctx.Err() != nil {
+			closePage(page)
+			return nil, ctx.Err()
+		}
+		// Rate limited and unavailable pages are reported by their status,
+		// the element missing on them is only a consequence.
+		if status := navigationStatus(page); status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			return nil, failPage(ctx, page, url, &PageError{Kind: PageErrorBlocked, URL: url, Err: &HTTPStatusError{URL: url, StatusCode: status}})
 		}
-		// Save the new cookies
-		SetCookies(store, baseURL, cookies)
+		return nil, failPage(ctx, page, url, &PageError{Kind: PageErrorSelectorMissing, URL: url, Err: fmt.Errorf("%w: %s: %w", ErrElementNotFound, elementToWaitFor, err)})
 	}
 
-	page := stealth.MustPage(browser)
-	page.MustSetViewport(1920, 1080, 2.0, false)
-
-	cookiesToSet := make([]*proto.NetworkCookieParam, len(cookies.Cookie))
-	for idx, c := range cookies.Cookie {
-		cookiesToSet[idx] = &proto.NetworkCookieParam{
-			Name:     c.Name,
-			Value:    c.Value,
-			Domain:   c.Domain,
-			Path:     c.Path,
-			Expires:  proto.TimeSinceEpoch(float64(c.Expiry)),
-			Secure:   c.Secure,
-			HTTPOnly: c.HttpOnly,
-			SameSite: proto.NetworkCookieSameSite(c.SameSite),
+	return page, nil
+}
+
+// newStealthPage opens a stealth page, in a context of its own when it goes
+// through a proxy.
+func newStealthPage(browser *rod.Browser, proxy *models.Proxy) (*rod.Page, error) {
+	if proxy == nil {
+		page, err := stealth.Page(browser)
+		if err != nil {
+			return nil, fmt.Errorf("error opening page: %w", err)
 		}
+		return page, nil
+	}
+
+	contextBrowser, err := newProxyContext(browser, *proxy)
+	if err != nil {
+		return nil, err
+	}
+	page, err := stealth.Page(contextBrowser)
+	if err != nil {
+		proto.TargetDisposeBrowserContext{BrowserContextID: contextBrowser.BrowserContextID}.Call(browser)
+		return nil, fmt.Errorf("error opening page: %w", err)
 	}
-	page.MustSetCookies(cookiesToSet...)
-
-	// Set the User-Agent if provided
-	if cookies.UserAgent != "" {
-		fmt.Printf("Setting User-Agent: %s\n", cookies.UserAgent)
-		page.MustSetUserAgent(&proto.NetworkSetUserAgentOverride{
-			UserAgent: cookies.UserAgent,
-		})
+	if err := handleProxyAuth(page, *proxy); err != nil {
+		destroyPage(page)
+		return nil, err
+	}
+	return page, nil
+}
+
+// navigatePage navigates page to url and waits for it to load.
+func navigatePage(ctx context.Context, page *rod.Page, url string) error {
+	if err := waitForRequest(ctx, url); err != nil {
+		return err
 	}
 
 	navCtx, navCancel := context.WithTimeout(ctx, 10*time.Second)
@@ -142,7 +172,7 @@ func GetStealthPage(ctx context.Context, browser *rod.Browser, url string, eleme
 		} else {
 			log.Printf("Error navigating to %s: %v", url, err)
 		}
-		return nil, err
+		return err
 	}
 
 	loadCtx, loadCancel := context.WithTimeout(ctx, 10*time.Second)
@@ -154,26 +184,11 @@ func GetStealthPage(ctx context.Context, browser *rod.Browser, url string, eleme
 		} else {
 			log.Printf("Error waiting for page to load: %v", err)
 		}
-		return nil, err
-	}
-
-	elementCtx, elementCancel := context.WithTimeout(ctx, 10*time.Second)
-	defer elementCancel()
-
-	_, err = page.Context(elementCtx).Element(elementToWaitFor)
-	if err != nil {
-		if err == context.DeadlineExceeded {
-			log.Printf("Timeout reached while waiting for element %s: %v", elementToWaitFor, err)
-			page.MustScreenshot("timeout_screenshot.png")
-		} else {
-			log.Printf("Error finding element %s: %v", elementToWaitFor, err)
-			page.MustScreenshot("error_screenshot.png")
-		}
-		return nil, err
+		return err
 	}
-
-	return page, nil
+	return nil
 }
+
 func ScrapeTest() (map[string]string, error) {
 	browser := GetBrowser()
 
@@ -182,6 +197,7 @@ func ScrapeTest() (map[string]string, error) {
 		fmt.Println(err)
 		return nil, err
 	}
+	defer closePage(page)
 	// get current page dimensions
 	defer func() {
 		if r := recover(); r != nil {
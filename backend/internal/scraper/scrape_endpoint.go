@@ -35,69 +35,130 @@ func (e ScrapeEndpointTestError) Error() string {
 
 // BEGIN: ScrapeEndpoint
 
-func ScrapeEndpoint(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, client *mongo.Client, browser *rod.Browser) ([]models.ScrapeResult, []models.ScrapeResult, error) {
+// ScrapeEndpoint scrapes the endpoint until ctx is done or the run is
+// cancelled through CancelRun. A cancelled run returns the results it
+// scraped so far.
+func ScrapeEndpoint(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, client *mongo.Client, browser *rod.Browser) ([]models.ScrapeResult, []models.ScrapeResult, models.ScrapeRunStats, error) {
+	ctx, endRun := startRun(ctx, relevantGroup.ID.Hex(), endpointToScrape.ID)
+	defer endRun()
+
 	var results []models.ScrapeResult
+	stats := &runStats{}
+	stats.setRunID(runInfoFrom(ctx).runId)
+
+	// A run cancelled while it waits for its slot ends without results.
+	releaseRun, err := acquireEndpointRun(ctx)
+	if err != nil {
+		stats.setCancelled()
+		fmt.Printf("Scrape of endpoint %s was cancelled before it started: %v\n", endpointToScrape.ID, err)
+		return nil, nil, stats.snapshot(), nil
+	}
+	defer releaseRun()
+
 	scrapeType := GetScrapeType(endpointToScrape)
+	fetcher := withRetries(withRobotsTxt(GetFetcher(endpointToScrape, browser), relevantGroup), endpointToScrape, stats)
+	archive := newElementArchive(ctx, endpointToScrape, relevantGroup)
 
 	switch scrapeType {
 	case PureDetails:
-		page, err := GetStealthPage(context.Background(), browser, endpointToScrape.URL, endpointToScrape.DetailedViewMainElementSelector)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error getting page: %w", err)
-		}
-		defer page.Close()
-
-		SlowScrollToBottom(page)
-		page.MustWaitStable()
-
-		elements, err := getMainElements(page, endpointToScrape, scrapeType, 1)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error finding elements: %w", err)
-		}
-
-		scraped, err := processElements(elements, endpointToScrape, relevantGroup)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error processing elements: %w", err)
+		scraped, err := scrapePureDetails(ctx, endpointToScrape, relevantGroup, fetcher, stats, archive)
+		if err != nil && ctx.Err() == nil {
+			return nil, nil, stats.snapshot(), err
 		}
 		results = scraped
+
 	case Previews:
-		scraped, err := scrapePreviewsPages(endpointToScrape, relevantGroup, browser)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error scraping previews pages: %w", err)
+		scraped, err := scrapePreviewsPages(ctx, endpointToScrape, relevantGroup, fetcher, stats, archive)
+		if err != nil && ctx.Err() == nil {
+			return nil, nil, stats.snapshot(), fmt.Errorf("error scraping previews pages: %w", err)
 		}
 		results = scraped
 
 	case PreviewsWithDetails:
-		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Minute)
 		defer cancel()
-		scraped := scrapePreviewsWithDetails(ctx, endpointToScrape, relevantGroup, browser)
+		scraped := scrapePreviewsWithDetails(ctx, endpointToScrape, relevantGroup, fetcher, stats, archive)
 
 		results = scraped
 
+	case MultiLevel:
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Minute)
+		defer cancel()
+		results = scrapeMultiLevel(ctx, endpointToScrape, relevantGroup, fetcher, stats)
+
 	default:
-		return nil, nil, fmt.Errorf("unknown scrape type: %v", scrapeType)
+		return nil, nil, stats.snapshot(), fmt.Errorf("unknown scrape type: %v", scrapeType)
+	}
+
+	if ctx.Err() != nil {
+		stats.setCancelled()
+		fmt.Printf("Scrape of endpoint %s was cancelled, keeping %d results\n", endpointToScrape.ID, len(results))
+	}
+	archive.save(ctx)
+
+	runStats := stats.snapshot()
+	fmt.Printf("Visited %d pages for endpoint %s\n", runStats.PagesVisited, endpointToScrape.ID)
+	for fieldID, count := range runStats.FallbackMatches {
+		fmt.Printf("Field %s used a fallback selector for %d results\n", fieldID, count)
+	}
+	for class, count := range runStats.Retries {
+		fmt.Printf("Retried %d page loads after %s errors\n", count, class)
+	}
+	if runStats.RetriesExhausted > 0 {
+		fmt.Printf("%d page loads failed after all retries\n", runStats.RetriesExhausted)
 	}
 
-	return filterElements(relevantGroup.Fields, results, endpointToScrape.ID, relevantGroup.ID, client)
+	filtered, toReplace, err := filterElements(relevantGroup.Fields, results, endpointToScrape.ID, relevantGroup.ID, client)
+	return filtered, toReplace, runStats, err
+}
+
+func scrapePureDetails(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher, stats *runStats, archive *elementArchive) ([]models.ScrapeResult, error) {
+	doc, err := fetcher.Fetch(ctx, endpointToScrape.URL, detailMainElementSelector(endpointToScrape), mainPageActions(endpointToScrape))
+	if err != nil {
+		return nil, fmt.Errorf("error getting page: %w", err)
+	}
+	defer doc.Close()
+	stats.setPagesVisited(1)
+
+	doc.ScrollToBottom()
+	doc.WaitStable()
+
+	elements, err := getMainElements(doc, fetcher, endpointToScrape, PureDetails, 1)
+	if err != nil {
+		return nil, fmt.Errorf("error finding elements: %w", err)
+	}
+
+	scraped, err := processElements(elements, endpointToScrape, relevantGroup, stats)
+	if err != nil {
+		return nil, fmt.Errorf("error processing elements: %w", err)
+	}
+	archive.addElements(elements, scraped)
+	return scraped, nil
 }
 
 func ScrapeEndpointTest(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, client *mongo.Client, browser *rod.Browser) ([]models.ScrapeResultTest, []models.ScrapeResultTest, error) {
 	fmt.Println("Scraping endpoint test")
+	releaseRun, err := acquireEndpointRun(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer releaseRun()
 
 	var results []models.ScrapeResultTest
 	scrapeType := GetScrapeType(endpointToScrape)
+	fetcher := withRobotsTxt(GetFetcher(endpointToScrape, browser), relevantGroup)
 	switch scrapeType {
 	case PureDetails:
-		page, err := GetStealthPage(context.Background(), browser, endpointToScrape.URL, endpointToScrape.DetailedViewMainElementSelector)
+		doc, err := fetcher.Fetch(context.Background(), endpointToScrape.URL, detailMainElementSelector(endpointToScrape), mainPageActions(endpointToScrape))
 		if err != nil {
 			return nil, nil, fmt.Errorf("error getting page: %w", err)
 		}
-		defer page.Close()
+		defer doc.Close()
 
-		SlowScrollToBottom(page)
-		page.MustWaitStable()
+		doc.ScrollToBottom()
+		doc.WaitStable()
 
-		elements, err := getMainElements(page, endpointToScrape, scrapeType, 1)
+		elements, err := getMainElements(doc, fetcher, endpointToScrape, scrapeType, 1)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error finding elements: %w", err)
 		}
@@ -109,19 +170,26 @@ func ScrapeEndpointTest(endpointToScrape models.Endpoint, relevantGroup models.S
 		results = scraped
 
 	case Previews:
-		scraped, err := scrapeTestPreviewsPages(endpointToScrape, relevantGroup, browser)
+		scraped, err := scrapeTestPreviewsPages(endpointToScrape, relevantGroup, fetcher)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error scraping previews pages: %w", err)
 		}
 		results = scraped
 
 	case PreviewsWithDetails:
-		scraped, err := scrapeTestPreviewsWithDetails(endpointToScrape, relevantGroup, browser)
+		scraped, err := scrapeTestPreviewsWithDetails(endpointToScrape, relevantGroup, fetcher)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error scraping previews with details: %w", err)
 		}
 		results = scraped
 
+	case MultiLevel:
+		scraped, err := scrapeTestMultiLevel(endpointToScrape, relevantGroup, fetcher)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error scraping multi level endpoint: %w", err)
+		}
+		results = scraped
+
 	default:
 		return nil, nil, fmt.Errorf("unknown scrape type: %v", scrapeType)
 	}
@@ -133,46 +201,48 @@ func ScrapeEndpointTest(endpointToScrape models.Endpoint, relevantGroup models.S
 
 // BEGIN: scrapePreviewsPages
 
-func scrapePreviewsPages(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, browser *rod.Browser) ([]models.ScrapeResult, error) {
+// scrapePreviewsPages returns the results of the pages visited before an
+// error together with the error.
+func scrapePreviewsPages(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher, stats *runStats, archive *elementArchive) ([]models.ScrapeResult, error) {
 	processedElements := []models.ScrapeResult{}
-	for i := endpointToScrape.PaginationConfig.Start; i <= endpointToScrape.PaginationConfig.End; i += endpointToScrape.PaginationConfig.Step {
-		urlWithPagination := buildPaginationURL(endpointToScrape.URL, endpointToScrape.PaginationConfig, i)
-		fmt.Println("Scraping URL: ", urlWithPagination)
-
-		page, err := GetStealthPage(context.TODO(), browser, urlWithPagination, endpointToScrape.MainElementSelector)
-		if err != nil {
-			return nil, fmt.Errorf("error getting page: %w", err)
+	seenHashes := map[string]bool{}
+	pagesVisited, err := visitListingPages(ctx, fetcher, endpointToScrape, stats, func(doc Document, elements []Element) error {
+		pageData := make([]PageData, len(elements))
+		for i, elem := range elements {
+			pageData[i] = PageData{Page: nil, Element: elem}
 		}
 
-		SlowScrollToBottom(page)
-		page.MustWaitStable()
+		processed, _ := processElements(pageData, endpointToScrape, relevantGroup, stats)
 
-		elements, err := getMainElements(page, endpointToScrape, Previews, -1)
-		if err != nil {
-			return nil, fmt.Errorf("error finding elements: %w", err)
+		hashes := make([]string, len(processed))
+		for i, result := range processed {
+			hashes[i] = result.UniqueHash
+		}
+		if endpointToScrape.PaginationConfig.OpenEnded && allSeen(seenHashes, hashes) {
+			fmt.Println("Page only contains results seen in this run, stopping pagination")
+			return errStopPagination
 		}
 
-		processed, _ := processElements(elements, endpointToScrape, relevantGroup)
-
+		archive.addElements(pageData, processed)
 		processedElements = append(processedElements, processed...)
-
-		page.MustClose()
-	}
-
-	return processedElements, nil
+		return nil
+	})
+	stats.setPagesVisited(pagesVisited)
+	return processedElements, err
 }
 
-func scrapeTestPreviewsPages(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, browser *rod.Browser) ([]models.ScrapeResultTest, error) {
+func scrapeTestPreviewsPages(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher) ([]models.ScrapeResultTest, error) {
 	var allElements []PageData
 
-	page, err := GetStealthPage(context.Background(), browser, endpointToScrape.URL, endpointToScrape.MainElementSelector)
+	doc, err := fetcher.Fetch(context.Background(), endpointToScrape.URL, mainElementSelector(endpointToScrape), mainPageActions(endpointToScrape))
 	if err != nil {
 		return nil, fmt.Errorf("error getting page: %w", err)
 	}
+	defer doc.Close()
 
-	SlowScrollToBottom(page)
-	page.MustWaitStable()
-	elements, err := getMainElements(page, endpointToScrape, Previews, 5)
+	doc.ScrollToBottom()
+	doc.WaitStable()
+	elements, err := getMainElements(doc, fetcher, endpointToScrape, Previews, 5)
 	if err != nil {
 		return nil, fmt.Errorf("error finding elements: %w", err)
 	}
@@ -180,7 +250,6 @@ func scrapeTestPreviewsPages(endpointToScrape models.Endpoint, relevantGroup mod
 	allElements = append(allElements, elements...)
 
 	processed, _ := processTestElements(allElements, endpointToScrape, relevantGroup)
-	page.MustClose()
 	return processed, nil
 
 }
@@ -189,34 +258,37 @@ func scrapeTestPreviewsPages(endpointToScrape models.Endpoint, relevantGroup mod
 
 // BEGIN: scrapePreviewsWithDetails
 
-func scrapePreviewsWithDetails(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, browser *rod.Browser) []models.ScrapeResult {
+func scrapePreviewsWithDetails(ctx context.Context, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher, stats *runStats, archive *elementArchive) []models.ScrapeResult {
 	var results []models.ScrapeResult
 	resultsChan := make(chan models.ScrapeResult)
-	sem := make(chan struct{}, 2)
+	sem := make(chan struct{}, detailConcurrency())
 	wg := sync.WaitGroup{}
+	seenLinks := map[string]bool{}
 
-	for i := endpointToScrape.PaginationConfig.Start; i <= endpointToScrape.PaginationConfig.End; i += endpointToScrape.PaginationConfig.Step {
-		urlWithPagination := buildPaginationURL(endpointToScrape.URL, endpointToScrape.PaginationConfig, i)
+	previewSelectors := scopeFieldSelectors(endpointToScrape.DetailFieldSelectors, models.FieldScopePreview)
 
-		page, err := GetStealthPage(ctx, browser, urlWithPagination, endpointToScrape.MainElementSelector)
-		if err != nil {
-			log.Printf("Error getting page: %v", err)
-			continue
-		}
-		defer page.Close()
+	pagesVisited, err := visitListingPages(ctx, fetcher, endpointToScrape, stats, func(doc Document, elems []Element) error {
+		// Detail links and preview fields are read before the listing page
+		// is left, the elements are not usable anymore once pagination moves
+		// on.
+		previews := getPreviewItems(elems, endpointToScrape, previewSelectors, relevantGroup.Fields, "detail", stats, archive != nil)
 
-		SlowScrollToBottom(page)
-		page.MustWaitStable()
+		detailLinks := make([]string, len(previews))
+		for i, preview := range previews {
+			detailLinks[i] = preview.link
+		}
 
-		elems, err := page.Elements(endpointToScrape.MainElementSelector)
-		if err != nil {
-			log.Printf("Error getting main elements: %v", err)
-			continue
+		// The unique hash of a result is only known after its detail page
+		// was scraped, so open ended pagination compares the detail links.
+		if endpointToScrape.PaginationConfig.OpenEnded && allSeen(seenLinks, detailLinks) {
+			fmt.Println("Page only contains detail links seen in this run, stopping pagination")
+			return errStopPagination
 		}
 
-		for _, elem := range elems {
+		for _, preview := range previews {
 			wg.Add(1)
-			go func(elem *rod.Element) {
+			go func(preview previewItem) {
+				fullUrl := preview.link
 				defer wg.Done()
 				select {
 				case sem <- struct{}{}:
@@ -225,46 +297,37 @@ func scrapePreviewsWithDetails(ctx context.Context, endpointToScrape models.Endp
 					return
 				}
 
-				linkElem, err := elem.Element(endpointToScrape.DetailedViewTriggerSelector)
-				if err != nil {
-					log.Printf("Error getting link element: %v", err)
-					return
-				}
-
-				attr, err := linkElem.Attribute("href")
-				if err != nil {
-					log.Printf("Error getting href attribute: %v", err)
-					return
-				}
-
-				fullUrl := helpers.GetFullUrl(endpointToScrape.URL, *attr)
-
-				detailPage, err := GetStealthPage(ctx, browser, fullUrl, endpointToScrape.DetailedViewMainElementSelector)
+				detailPage, err := fetcher.Fetch(ctx, fullUrl, detailMainElementSelector(endpointToScrape), detailPageActions(endpointToScrape))
 				if err != nil {
 					log.Printf("Error getting detailed view page: %v", err)
 					return
 				}
+				defer detailPage.Close()
 
-				detailPage.MustWaitStable()
-				detailElem := detailPage.MustElement(endpointToScrape.DetailedViewMainElementSelector)
-				if detailElem == nil {
+				detailPage.WaitStable()
+				detailElem, err := detailPage.Element(detailMainElementSelector(endpointToScrape))
+				if err != nil {
 					log.Printf("Detailed view element is null")
 					return
 				}
 
-				pageData := []PageData{{Page: nil, Element: detailElem, ActualLink: fullUrl}}
-				pageResults, err := processElements(pageData, endpointToScrape, relevantGroup)
+				result, err := detailResult(preview.details, detailElem, fullUrl, endpointToScrape, relevantGroup, stats)
 				if err != nil {
 					log.Printf("Error processing detail page: %v", err)
 					return
 				}
-
-				for _, pgResult := range pageResults {
-					resultsChan <- pgResult
+				if archive != nil {
+					archive.add(result, fullUrl, preview.html, detailElem.HTML())
 				}
-				detailPage.MustClose()
-			}(elem)
+
+				resultsChan <- result
+			}(preview)
 		}
+		return nil
+	})
+	stats.setPagesVisited(pagesVisited)
+	if err != nil {
+		log.Printf("Error paginating %s: %v", endpointToScrape.URL, err)
 	}
 
 	go func() {
@@ -279,33 +342,140 @@ func scrapePreviewsWithDetails(ctx context.Context, endpointToScrape models.Endp
 	return results
 }
 
-func scrapeTestPreviewsWithDetails(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, browser *rod.Browser) ([]models.ScrapeResultTest, error) {
+// allSeen marks the keys as seen and reports whether every one of them had
+// been seen before. An empty list counts as seen.
+func allSeen(seen map[string]bool, keys []string) bool {
+	all := true
+	for _, key := range keys {
+		if !seen[key] {
+			all = false
+			seen[key] = true
+		}
+	}
+	return all
+}
+
+// detailResult merges the fields extracted from a preview with the fields of
+// its detail element and sets the link field to the detail page.
+func detailResult(previewDetails []interface{}, detailElem Element, link string, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, stats *runStats) (models.ScrapeResult, error) {
+	detailSelectors := scopeFieldSelectors(endpointToScrape.DetailFieldSelectors, models.FieldScopeDetail)
+	details, err := getElementDetails(detailElem, detailSelectors, relevantGroup.Fields, stats)
+	if err != nil {
+		return models.ScrapeResult{}, err
+	}
+
+	details = append(toScrapeResultDetails(previewDetails), details...)
+	linkFieldId := findLinkFieldId(relevantGroup.Fields)
+	for i, detail := range details {
+		if detail.FieldID == linkFieldId {
+			details[i].Value = link
+		}
+	}
+	return newScrapeResult(details, endpointToScrape, relevantGroup), nil
+}
+
+// previewItem is a listing element of a previews with details endpoint with
+// the fields extracted from the preview and the link to its detail page.
+// html is only kept for archiving endpoints.
+type previewItem struct {
+	link    string
+	details []interface{}
+	html    string
+}
+
+// getPreviewItems resolves the detail link of every main element and
+// extracts the preview scoped fields.
+func getPreviewItems(elems []Element, endpointToScrape models.Endpoint, previewSelectors []models.FieldSelector, fields []models.Field, detailType string, stats *runStats, keepHTML bool) []previewItem {
+	items := make([]previewItem, 0, len(elems))
+	for _, elem := range elems {
+		link, err := getTriggerLink(elem, detailTriggerSelector(endpointToScrape), endpointToScrape.URL)
+		if err != nil {
+			log.Printf("Error getting detail link: %v", err)
+			continue
+		}
+
+		details, err := createDetails(elem, previewSelectors, fields, detailType, stats)
+		if err != nil {
+			log.Printf("Error getting preview details: %v", err)
+			continue
+		}
+
+		item := previewItem{link: link, details: details}
+		if keepHTML {
+			item.html = elem.HTML()
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// scopeFieldSelectors returns the field selectors extracted from the given
+// scope. Selectors without a scope belong to the detail element.
+func scopeFieldSelectors(selectors []models.FieldSelector, scope models.FieldScope) []models.FieldSelector {
+	scoped := []models.FieldSelector{}
+	for _, selector := range selectors {
+		selectorScope := selector.Scope
+		if selectorScope == "" {
+			selectorScope = models.FieldScopeDetail
+		}
+		if selectorScope == scope {
+			scoped = append(scoped, selector)
+		}
+	}
+	return scoped
+}
+
+// getTriggerLink resolves the href of the trigger element inside elem. An
+// empty trigger selector uses the href of elem itself.
+func getTriggerLink(elem Element, trigger Selector, baseURL string) (string, error) {
+	linkElem := elem
+	if !trigger.IsEmpty() {
+		found, err := elem.Element(trigger)
+		if err != nil {
+			return "", fmt.Errorf("error getting link element: %w", err)
+		}
+		linkElem = found
+	}
+
+	attr, err := linkElem.Attribute("href")
+	if err != nil || attr == nil {
+		return "", fmt.Errorf("error getting href attribute: %v", err)
+	}
+
+	return helpers.GetFullUrl(baseURL, *attr), nil
+}
+
+func scrapeTestPreviewsWithDetails(endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, fetcher Fetcher) ([]models.ScrapeResultTest, error) {
 	var results []models.ScrapeResultTest
 	resultsChan := make(chan models.ScrapeResultTest)
-	sem := make(chan struct{}, 2)
+	sem := make(chan struct{}, detailConcurrency())
 	wg := sync.WaitGroup{}
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
 	defer cancel()
-	page, err := GetStealthPage(ctx, browser, endpointToScrape.URL, endpointToScrape.MainElementSelector)
+	doc, err := fetcher.Fetch(ctx, endpointToScrape.URL, mainElementSelector(endpointToScrape), mainPageActions(endpointToScrape))
 	if err != nil {
 		return nil, fmt.Errorf("error getting page: %w", err)
 	}
-	defer page.Close()
+	defer doc.Close()
 
-	SlowScrollToBottom(page)
-	page.MustWaitStable()
+	doc.ScrollToBottom()
+	doc.WaitStable()
 
-	elems, err := page.Elements(endpointToScrape.MainElementSelector)
+	elems, err := doc.Elements(mainElementSelector(endpointToScrape))
 	if err != nil {
 		return nil, fmt.Errorf("error getting main elements: %w", err)
 	}
+	if len(elems) > 5 {
+		elems = elems[:5] // Limit to 5 elements for testing
+	}
 
-	for i, elem := range elems {
-		if i >= 5 {
-			break // Limit to 5 elements for testing
-		}
+	previewSelectors := scopeFieldSelectors(endpointToScrape.DetailFieldSelectors, models.FieldScopePreview)
+	detailSelectors := scopeFieldSelectors(endpointToScrape.DetailFieldSelectors, models.FieldScopeDetail)
+	linkFieldId := findLinkFieldId(relevantGroup.Fields)
+
+	for _, preview := range getPreviewItems(elems, endpointToScrape, previewSelectors, relevantGroup.Fields, "test", nil, false) {
 		wg.Add(1)
-		go func(elem *rod.Element) {
+		go func(preview previewItem) {
 			defer wg.Done()
 			select {
 			case sem <- struct{}{}:
@@ -313,49 +483,42 @@ func scrapeTestPreviewsWithDetails(endpointToScrape models.Endpoint, relevantGro
 			case <-ctx.Done():
 				return
 			}
-			linkElem, err := elem.Element(endpointToScrape.DetailedViewTriggerSelector)
-			if err != nil {
-				fmt.Printf("error getting link element: %v", err)
-				return
-			}
-
-			attr, err := linkElem.Attribute("href")
-			if err != nil {
-				fmt.Printf("error getting href attribute: %v", err)
-				return
-			}
 
-			fullUrl := helpers.GetFullUrl(endpointToScrape.URL, *attr)
+			fullUrl := preview.link
 			fmt.Println("Full URL: ", fullUrl)
 
-			detailPage, err := GetStealthPage(context.Background(), browser, fullUrl, endpointToScrape.DetailedViewMainElementSelector)
+			detailPage, err := fetcher.Fetch(context.Background(), fullUrl, detailMainElementSelector(endpointToScrape), detailPageActions(endpointToScrape))
 			if err != nil {
 				fmt.Printf("error getting detailed view page: %v", err)
 				return
 			}
+			defer detailPage.Close()
 
-			detailPage.MustWaitStable()
+			detailPage.WaitStable()
 
-			detailElem := detailPage.MustElement(endpointToScrape.DetailedViewMainElementSelector)
-			if detailElem == nil {
+			detailElem, err := detailPage.Element(detailMainElementSelector(endpointToScrape))
+			if err != nil {
 				fmt.Println("Detailed view element is null")
-				detailPage.Close()
 				return
 			}
 
-			pageData := []PageData{{Page: nil, Element: detailElem, ActualLink: fullUrl}}
-			pageResults, err := processTestElements(pageData, endpointToScrape, relevantGroup)
+			details, err := getElementDetailsTest(detailElem, detailSelectors, relevantGroup.Fields)
 			if err != nil {
 				fmt.Printf("error processing detail page: %v", err)
-				detailPage.Close()
 				return
 			}
 
-			for _, result := range pageResults {
+			details = append(toScrapeResultDetailsTest(preview.details), details...)
+			for i, detail := range details {
+				if detail.FieldID == linkFieldId {
+					details[i].Value = fullUrl
+				}
+			}
+
+			if result, ok := newScrapeResultTest(details, endpointToScrape, relevantGroup); ok {
 				resultsChan <- result
 			}
-			detailPage.MustClose()
-		}(elem)
+		}(preview)
 	}
 
 	go func() {
@@ -374,27 +537,68 @@ func scrapeTestPreviewsWithDetails(endpointToScrape models.Endpoint, relevantGro
 
 // BEGIN: getElementDetails
 
+// processFieldSelectors tries the field's selector and then its fallbacks
+// until one yields a value. It returns the index of the selector that matched,
+// 0 being the primary selector, or -1 when none did.
+func processFieldSelectors(element Element, selector models.FieldSelector, fieldType models.FieldType) (interface{}, []string, int, error) {
+	var primaryText interface{}
+	var primaryMatches []string
+
+	for i, alternative := range selectorAlternatives(selector) {
+		var text interface{}
+		var extractMatches []string
+		var err error
+		if fieldType == models.FieldTypeList {
+			var items []string
+			items, extractMatches, err = processElementListText(element, alternative)
+			if err == nil && len(nonEmptyItems(items)) > 0 {
+				return items, extractMatches, i, nil
+			}
+			text = items
+		} else if number, ok := elementNumber(element, alternative); ok && fieldType == models.FieldTypeNumber {
+			return number, nil, i, nil
+		} else {
+			text, extractMatches, err = processElementText(element, alternative)
+			if err == nil && strings.TrimSpace(text.(string)) != "" {
+				return text, extractMatches, i, nil
+			}
+		}
+		if err != nil {
+			return nil, nil, -1, err
+		}
+		if i == 0 {
+			primaryText, primaryMatches = text, extractMatches
+		}
+	}
+
+	return primaryText, primaryMatches, -1, nil
+}
+
+// selectorAlternatives returns the field selector followed by its fallbacks,
+// each as a complete field selector.
+func selectorAlternatives(selector models.FieldSelector) []models.FieldSelector {
+	alternatives := make([]models.FieldSelector, 0, len(selector.Fallbacks)+1)
+	alternatives = append(alternatives, selector)
+	for _, fallback := range selector.Fallbacks {
+		alternative := selector
+		alternative.Selector = fallback.Selector
+		alternative.SelectorLanguage = fallback.SelectorLanguage
+		alternative.Regex = fallback.Regex
+		alternative.RegexMatchIndexToUse = fallback.RegexMatchIndexToUse
+		alternative.AttributeToGet = fallback.AttributeToGet
+		alternatives = append(alternatives, alternative)
+	}
+	return alternatives
+}
+
 // Common function to get text from element with optional attribute and regex processing
-func processElementText(element *rod.Element, selector models.FieldSelector) (interface{}, []string, error) {
+func processElementText(element Element, selector models.FieldSelector) (interface{}, []string, error) {
 	var text interface{} = ""
 	var extractMatches []string
 
-	fieldElement, err := element.Element(selector.Selector)
+	fieldElement, err := element.Element(fieldSelector(selector))
 	if err == nil {
-		text = fieldElement.MustEval("() => this.textContent").String()
-		if strings.TrimSpace(selector.AttributeToGet) != "" {
-			if attr, err := fieldElement.Attribute(selector.AttributeToGet); err == nil && attr != nil {
-				text = *attr
-			}
-		}
-		if strings.TrimSpace(selector.Regex) != "" {
-			// fmt.Println("Original text: ", text, selector.Regex)
-			if extractedText, matches, err := helpers.ExtractStringWithRegex(text.(string), selector.Regex, selector.RegexMatchIndexToUse); err == nil {
-				// fmt.Println("Extracted text: ", extractedText)
-				text = extractedText
-				extractMatches = matches
-			}
-		}
+		text, extractMatches = extractFieldText(fieldElement, selector)
 	}
 
 	if strings.TrimSpace(text.(string)) == "" {
@@ -414,6 +618,76 @@ func processElementText(element *rod.Element, selector models.FieldSelector) (in
 	return text, extractMatches, nil
 }
 
+// nonEmptyItems returns the list items that are not blank.
+func nonEmptyItems(items []string) []string {
+	nonEmpty := make([]string, 0, len(items))
+	for _, item := range items {
+		if strings.TrimSpace(item) != "" {
+			nonEmpty = append(nonEmpty, item)
+		}
+	}
+	return nonEmpty
+}
+
+// elementNumber returns the number a field element holds as a typed value,
+// which only JSON values do. Selectors with a regex work on the text.
+func elementNumber(element Element, selector models.FieldSelector) (float64, bool) {
+	if strings.TrimSpace(selector.Regex) != "" {
+		return 0, false
+	}
+	fieldElement, err := element.Element(fieldSelector(selector))
+	if err != nil {
+		return 0, false
+	}
+	numeric, ok := fieldElement.(numberElement)
+	if !ok {
+		return 0, false
+	}
+	return numeric.number(strings.TrimSpace(selector.AttributeToGet))
+}
+
+// processElementListText collects the text of every element matching the
+// selector for list fields. Empty items are kept so the default transform
+// can fill them, createDetails drops them after the transforms.
+func processElementListText(element Element, selector models.FieldSelector) ([]string, []string, error) {
+	items := []string{}
+	var extractMatches []string
+
+	fieldElements, err := element.Elements(fieldSelector(selector))
+	if err != nil {
+		return items, nil, nil
+	}
+
+	for _, fieldElement := range fieldElements {
+		text, matches := extractFieldText(fieldElement, selector)
+		items = append(items, text)
+		extractMatches = append(extractMatches, matches...)
+	}
+
+	return items, extractMatches, nil
+}
+
+// extractFieldText reads the text or configured attribute of a matched field
+// element and applies the selector's regex to it.
+func extractFieldText(fieldElement Element, selector models.FieldSelector) (string, []string) {
+	text := fieldElement.Text()
+	if strings.TrimSpace(selector.AttributeToGet) != "" {
+		if attr, err := fieldElement.Attribute(selector.AttributeToGet); err == nil && attr != nil {
+			text = *attr
+		}
+	}
+
+	var extractMatches []string
+	if strings.TrimSpace(selector.Regex) != "" {
+		if extractedText, matches, err := helpers.ExtractStringWithRegex(text, selector.Regex, selector.RegexMatchIndexToUse); err == nil {
+			text = extractedText
+			extractMatches = matches
+		}
+	}
+
+	return text, extractMatches
+}
+
 // Common function to find field type
 func getFieldType(fields []models.Field, fieldID string) models.FieldType {
 	for _, field := range fields {
@@ -425,22 +699,49 @@ func getFieldType(fields []models.Field, fieldID string) models.FieldType {
 }
 
 // General function to create details based on result type
-func createDetails(element *rod.Element, selectors []models.FieldSelector, fields []models.Field, detailType string) ([]interface{}, error) {
+func createDetails(element Element, selectors []models.FieldSelector, fields []models.Field, detailType string, stats *runStats) ([]interface{}, error) {
 	var details []interface{}
 
 	for _, selector := range selectors {
-		text, extractMatches, err := processElementText(element, selector)
+		var relevantFieldType models.FieldType = getFieldType(fields, selector.FieldID)
+
+		text, extractMatches, matchedIndex, err := processFieldSelectors(element, selector, relevantFieldType)
 		if err != nil {
 			return nil, err
 		}
+		if matchedIndex > 0 {
+			stats.recordFallbackMatch(selector.FieldID)
+		}
 
-		var relevantFieldType models.FieldType = getFieldType(fields, selector.FieldID)
-		if relevantFieldType == "number" {
-			if text != "" {
-				text = helpers.CastPriceStringToFloat(text.(string))
+		var transformSteps []models.TransformStep
+		if len(selector.Transforms) > 0 {
+			text, transformSteps = applyTransforms(text, selector.Transforms)
+		}
+		if items, ok := text.([]string); ok {
+			text = nonEmptyItems(items)
+		}
+
+		var parseError string
+		switch relevantFieldType {
+		case "number":
+			// Typed numbers are kept, only text is parsed as a price.
+			if value, ok := text.(string); ok {
+				if value != "" {
+					text = helpers.CastPriceStringToFloat(value)
+				} else {
+					text = 0.0
+				}
+			}
+		case models.FieldTypeDate:
+			// Dates that cannot be parsed are stored as null.
+			if date, err := helpers.ParseDateString(text.(string), selector.DateParsing, time.Now()); err == nil {
+				text = date
 			} else {
-				text = 0.0
+				parseError = err.Error()
+				text = nil
 			}
+		case models.FieldTypeBoolean:
+			text = helpers.ParseBooleanString(text.(string), selector.BooleanParsing)
 		}
 
 		id := uuid.New().String()
@@ -453,15 +754,29 @@ func createDetails(element *rod.Element, selectors []models.FieldSelector, field
 			})
 		case "test":
 			rawData := ""
-			if fieldElement, _ := element.Element(selector.Selector); fieldElement != nil {
-				rawData = fieldElement.MustHTML()
+			matchedSelector := selector
+			if matchedIndex > 0 {
+				matchedSelector = selectorAlternatives(selector)[matchedIndex]
+			}
+			if relevantFieldType == models.FieldTypeList {
+				fieldElements, _ := element.Elements(fieldSelector(matchedSelector))
+				rawHTML := make([]string, len(fieldElements))
+				for i, fieldElement := range fieldElements {
+					rawHTML[i] = fieldElement.HTML()
+				}
+				rawData = strings.Join(rawHTML, "\n")
+			} else if fieldElement, _ := element.Element(fieldSelector(matchedSelector)); fieldElement != nil {
+				rawData = fieldElement.HTML()
 			}
 			details = append(details, models.ScrapeResultDetailTest{
-				ID:           id,
-				FieldID:      selector.FieldID,
-				Value:        text,
-				RawData:      rawData,
-				RegexMatches: extractMatches,
+				ID:                   id,
+				FieldID:              selector.FieldID,
+				Value:                text,
+				RawData:              rawData,
+				RegexMatches:         extractMatches,
+				ParseError:           parseError,
+				MatchedSelectorIndex: matchedIndex,
+				TransformSteps:       transformSteps,
 			})
 		}
 	}
@@ -469,30 +784,38 @@ func createDetails(element *rod.Element, selectors []models.FieldSelector, field
 	return details, nil
 }
 
-func getElementDetails(element *rod.Element, selectors []models.FieldSelector, fields []models.Field) ([]models.ScrapeResultDetail, error) {
-	details, err := createDetails(element, selectors, fields, "detail")
+func getElementDetails(element Element, selectors []models.FieldSelector, fields []models.Field, stats *runStats) ([]models.ScrapeResultDetail, error) {
+	details, err := createDetails(element, selectors, fields, "detail", stats)
+	if err != nil {
+		return nil, err
+	}
+	return toScrapeResultDetails(details), nil
+}
+
+func getElementDetailsTest(element Element, selectors []models.FieldSelector, fields []models.Field) ([]models.ScrapeResultDetailTest, error) {
+	details, err := createDetails(element, selectors, fields, "test", nil)
 	if err != nil {
 		return nil, err
 	}
-	// Type assert details to []models.ScrapeResultDetail
+	return toScrapeResultDetailsTest(details), nil
+}
+
+// Type assert details created with detail type "detail"
+func toScrapeResultDetails(details []interface{}) []models.ScrapeResultDetail {
 	result := make([]models.ScrapeResultDetail, len(details))
 	for i, d := range details {
 		result[i] = d.(models.ScrapeResultDetail)
 	}
-	return result, nil
+	return result
 }
 
-func getElementDetailsTest(element *rod.Element, selectors []models.FieldSelector, fields []models.Field) ([]models.ScrapeResultDetailTest, error) {
-	details, err := createDetails(element, selectors, fields, "test")
-	if err != nil {
-		return nil, err
-	}
-	// Type assert details to []models.ScrapeResultDetailTest
+// Type assert details created with detail type "test"
+func toScrapeResultDetailsTest(details []interface{}) []models.ScrapeResultDetailTest {
 	result := make([]models.ScrapeResultDetailTest, len(details))
 	for i, d := range details {
 		result[i] = d.(models.ScrapeResultDetailTest)
 	}
-	return result, nil
+	return result
 }
 
 // END: getElementDetails
@@ -517,32 +840,41 @@ func processTestElements(elements []PageData, endpointToScrape models.Endpoint,
 			}
 		}
 
-		uniqueId := getFieldValueByFieldKeyTest(relevantGroup.Fields, "unique_identifier", details).(string)
-		if uniqueId == "" {
-			fmt.Println("Unique ID is empty, skipping")
+		result, ok := newScrapeResultTest(details, endpointToScrape, relevantGroup)
+		if !ok {
 			continue
 		}
-
-		result := models.ScrapeResultTest{
-			ID:         primitive.NewObjectID(),
-			UniqueHash: helpers.GenerateScrapeResultHash(endpointToScrape.ID + uniqueId),
-			EndpointID: endpointToScrape.ID,
-			GroupId:    relevantGroup.ID,
-			Fields:     details,
-			Timestamp:  time.Now().Format(time.RFC3339),
-		}
 		results = append(results, result)
 	}
 
 	return results, nil
 }
 
-func processElements(elements []PageData, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup) ([]models.ScrapeResult, error) {
+// newScrapeResultTest builds a test result from its details. It returns false
+// when the unique identifier is empty.
+func newScrapeResultTest(details []models.ScrapeResultDetailTest, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup) (models.ScrapeResultTest, bool) {
+	uniqueId := helpers.FormatFieldValue(getFieldValueByFieldKeyTest(relevantGroup.Fields, "unique_identifier", details))
+	if uniqueId == "" {
+		fmt.Println("Unique ID is empty, skipping")
+		return models.ScrapeResultTest{}, false
+	}
+
+	return models.ScrapeResultTest{
+		ID:         primitive.NewObjectID(),
+		UniqueHash: helpers.GenerateScrapeResultHash(endpointToScrape.ID + uniqueId),
+		EndpointID: endpointToScrape.ID,
+		GroupId:    relevantGroup.ID,
+		Fields:     details,
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}, true
+}
+
+func processElements(elements []PageData, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup, stats *runStats) ([]models.ScrapeResult, error) {
 	results := []models.ScrapeResult{}
 	linkFieldId := findLinkFieldId(relevantGroup.Fields)
 
 	for _, element := range elements {
-		details, err := getElementDetails(element.Element, endpointToScrape.DetailFieldSelectors, relevantGroup.Fields)
+		details, err := getElementDetails(element.Element, endpointToScrape.DetailFieldSelectors, relevantGroup.Fields, stats)
 		if err != nil {
 			return nil, fmt.Errorf("error getting element details: %w", err)
 		}
@@ -555,20 +887,23 @@ func processElements(elements []PageData, endpointToScrape models.Endpoint, rele
 			}
 		}
 
-		result := models.ScrapeResult{
-			ID:                  primitive.NewObjectID(),
-			UniqueHash:          helpers.GenerateScrapeResultHash(endpointToScrape.ID + getFieldValueByFieldKey(relevantGroup.Fields, "unique_identifier", details).(string)),
-			EndpointID:          endpointToScrape.ID,
-			GroupId:             relevantGroup.ID,
-			Fields:              details,
-			TimestampInitial:    time.Now().Format(time.RFC3339),
-			TimestampLastUpdate: time.Now().Format(time.RFC3339),
-		}
-		results = append(results, result)
+		results = append(results, newScrapeResult(details, endpointToScrape, relevantGroup))
 	}
 	return results, nil
 }
 
+func newScrapeResult(details []models.ScrapeResultDetail, endpointToScrape models.Endpoint, relevantGroup models.ScrapeGroup) models.ScrapeResult {
+	return models.ScrapeResult{
+		ID:                  primitive.NewObjectID(),
+		UniqueHash:          helpers.GenerateScrapeResultHash(endpointToScrape.ID + uniqueIdentifier(relevantGroup.Fields, details)),
+		EndpointID:          endpointToScrape.ID,
+		GroupId:             relevantGroup.ID,
+		Fields:              details,
+		TimestampInitial:    time.Now().Format(time.RFC3339),
+		TimestampLastUpdate: time.Now().Format(time.RFC3339),
+	}
+}
+
 // END: processTestElements
 
 // COMMON FUNCTIONS used in both Normal and Test Mode
@@ -577,8 +912,7 @@ func filterElements(fields []models.Field, results []models.ScrapeResult, endpoi
 	var filtered []models.ScrapeResult
 	var toReplace []models.ScrapeResult
 	for _, element := range results {
-		uniqueId := getFieldValueByFieldKey(fields, "unique_identifier", element.Fields)
-		if uniqueId == "" {
+		if uniqueIdentifier(fields, element.Fields) == "" {
 			fmt.Println("Unique ID is empty, skipping")
 			continue
 		}
@@ -672,6 +1006,12 @@ func getFieldValueByFieldKey(fields []models.Field, fieldKey string, details []m
 	return ""
 }
 
+// uniqueIdentifier returns the unique_identifier value of a result as text,
+// whatever the type of its field.
+func uniqueIdentifier(fields []models.Field, details []models.ScrapeResultDetail) string {
+	return helpers.FormatFieldValue(getFieldValueByFieldKey(fields, "unique_identifier", details))
+}
+
 func getFieldValueByFieldKeyTest(fields []models.Field, fieldKey string, details []models.ScrapeResultDetailTest) interface{} {
 	for _, field := range fields {
 		if field.Key == fieldKey {
@@ -0,0 +1,210 @@
+package scraper
+
+import (
+	"reflect"
+	"scrapeit/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// parseHTMLElement returns the document parsed from html as an Element.
+func parseHTMLElement(t *testing.T, html string) Element {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newHTMLElement(doc.Selection)
+}
+
+const productCard = `<div class="card">
+	<h2 class="title-new">New title</h2>
+	<p class="subtitle"> </p>
+	<span class="price" data-price="12.50"></span>
+	<ul class="tags"><li>a</li><li>b</li></ul>
+</div>`
+
+func TestProcessFieldSelectors(t *testing.T) {
+	element := parseHTMLElement(t, productCard)
+	fallback := func(selector string) models.SelectorFallback {
+		return models.SelectorFallback{Selector: selector}
+	}
+
+	tests := []struct {
+		name      string
+		selector  models.FieldSelector
+		fieldType models.FieldType
+		want      interface{}
+		wantIndex int
+	}{
+		{
+			name:      "primary selector",
+			selector:  models.FieldSelector{Selector: "h2.title-new", Fallbacks: []models.SelectorFallback{fallback("h2")}},
+			want:      "New title",
+			wantIndex: 0,
+		},
+		{
+			name:      "fallbacks are tried in order",
+			selector:  models.FieldSelector{Selector: "h1.title", Fallbacks: []models.SelectorFallback{fallback("h2.title"), fallback("h2.title-new"), fallback("h2")}},
+			want:      "New title",
+			wantIndex: 2,
+		},
+		{
+			name:      "blank primary value",
+			selector:  models.FieldSelector{Selector: "p.subtitle", Fallbacks: []models.SelectorFallback{fallback("h2")}},
+			want:      "New title",
+			wantIndex: 1,
+		},
+		{
+			name:      "fallback attribute",
+			selector:  models.FieldSelector{Selector: "span.cost", Fallbacks: []models.SelectorFallback{{Selector: "span.price", AttributeToGet: "data-price"}}},
+			want:      "12.50",
+			wantIndex: 1,
+		},
+		{
+			name:      "xpath fallback",
+			selector:  models.FieldSelector{Selector: "h1", Fallbacks: []models.SelectorFallback{{Selector: `//h2[contains(@class, "title")]`, SelectorLanguage: models.SelectorLanguageXPath}}},
+			want:      "New title",
+			wantIndex: 1,
+		},
+		{
+			name:      "nothing matches",
+			selector:  models.FieldSelector{Selector: "h1", Fallbacks: []models.SelectorFallback{fallback("h3")}},
+			want:      "",
+			wantIndex: -1,
+		},
+		{
+			name:      "list fallback",
+			selector:  models.FieldSelector{Selector: "ol li", Fallbacks: []models.SelectorFallback{fallback("ul.tags li")}},
+			fieldType: models.FieldTypeList,
+			want:      []string{"a", "b"},
+			wantIndex: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, index, err := processFieldSelectors(element, tt.selector, tt.fieldType)
+			if err != nil {
+				t.Fatalf("processFieldSelectors() error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+			if index != tt.wantIndex {
+				t.Errorf("matched index = %d, want %d", index, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestFallbackMatchDetails(t *testing.T) {
+	element := parseHTMLElement(t, productCard)
+	fields := []models.Field{{ID: "title", Type: models.FieldTypeText}, {ID: "price", Type: models.FieldTypeNumber}}
+	selectors := []models.FieldSelector{
+		{FieldID: "title", Selector: "h1", Fallbacks: []models.SelectorFallback{{Selector: "h2.title-new"}}},
+		{FieldID: "price", Selector: "span.price", AttributeToGet: "data-price"},
+	}
+
+	details, err := getElementDetailsTest(element, selectors, fields)
+	if err != nil {
+		t.Fatalf("getElementDetailsTest() error: %v", err)
+	}
+	if got := details[0].MatchedSelectorIndex; got != 1 {
+		t.Errorf("title matched index = %d, want 1", got)
+	}
+	if got := details[0].RawData; got != `<h2 class="title-new">New title</h2>` {
+		t.Errorf("title raw data = %q, want the fallback's element", got)
+	}
+	if got := details[1].MatchedSelectorIndex; got != 0 {
+		t.Errorf("price matched index = %d, want 0", got)
+	}
+
+	stats := &runStats{}
+	if _, err := getElementDetails(element, selectors, fields, stats); err != nil {
+		t.Fatalf("getElementDetails() error: %v", err)
+	}
+	if got := stats.snapshot().FallbackMatches; !reflect.DeepEqual(got, map[string]int{"title": 1}) {
+		t.Errorf("fallback matches = %v, want title once", got)
+	}
+}
+
+func TestScopeFieldSelectors(t *testing.T) {
+	selectors := []models.FieldSelector{
+		{FieldID: "title"},
+		{FieldID: "price", Scope: models.FieldScopePreview},
+		{FieldID: "description", Scope: models.FieldScopeDetail},
+	}
+
+	fieldIDs := func(selectors []models.FieldSelector) []string {
+		ids := []string{}
+		for _, selector := range selectors {
+			ids = append(ids, selector.FieldID)
+		}
+		return ids
+	}
+	if got := fieldIDs(scopeFieldSelectors(selectors, models.FieldScopePreview)); !reflect.DeepEqual(got, []string{"price"}) {
+		t.Errorf("preview selectors = %v, want [price]", got)
+	}
+	if got := fieldIDs(scopeFieldSelectors(selectors, models.FieldScopeDetail)); !reflect.DeepEqual(got, []string{"title", "description"}) {
+		t.Errorf("detail selectors = %v, want [title description]", got)
+	}
+}
+
+func TestPreviewDetailMerge(t *testing.T) {
+	listing := parseHTMLElement(t, `<ul>
+		<li class="card"><a href="/p/1">Item 1</a><span class="price">10,00 €</span></li>
+		<li class="card"><span class="price">5,00 €</span></li>
+		<li class="card"><a href="https://other.test/p/2">Item 2</a><span class="price">20,00 €</span></li>
+	</ul>`)
+	detail := parseHTMLElement(t, `<div class="detail"><h1>First item</h1><p class="price">99,00 €</p></div>`)
+
+	fields := []models.Field{
+		{ID: "title", Key: "unique_identifier", Type: models.FieldTypeText},
+		{ID: "price", Type: models.FieldTypeNumber},
+		{ID: "link", Name: "Link", Type: models.FieldTypeLink},
+	}
+	endpoint := models.Endpoint{
+		ID:                          "endpoint",
+		URL:                         "https://shop.test/list",
+		DetailedViewTriggerSelector: "a",
+		DetailFieldSelectors: []models.FieldSelector{
+			{FieldID: "title", Selector: "h1"},
+			{FieldID: "price", Selector: "span.price", Scope: models.FieldScopePreview},
+			{FieldID: "link", Selector: "a.missing", Scope: models.FieldScopeDetail},
+		},
+	}
+	group := models.ScrapeGroup{Fields: fields}
+
+	elems, err := listing.Elements(CSS("li.card"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previews := getPreviewItems(elems, endpoint, scopeFieldSelectors(endpoint.DetailFieldSelectors, models.FieldScopePreview), fields, "detail", nil, false)
+
+	// The card without a link has no detail page to merge with.
+	if len(previews) != 2 {
+		t.Fatalf("got %d previews, want 2", len(previews))
+	}
+	if previews[0].link != "https://shop.test/p/1" || previews[1].link != "https://other.test/p/2" {
+		t.Errorf("links = %q, %q", previews[0].link, previews[1].link)
+	}
+
+	result, err := detailResult(previews[0].details, detail, previews[0].link, endpoint, group, nil)
+	if err != nil {
+		t.Fatalf("detailResult() error: %v", err)
+	}
+	got := map[string]interface{}{}
+	for _, field := range result.Fields {
+		got[field.FieldID] = field.Value
+	}
+	want := map[string]interface{}{"price": 10.0, "title": "First item", "link": "https://shop.test/p/1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+	if len(result.Fields) != len(want) {
+		t.Errorf("got %d fields, want %d", len(result.Fields), len(want))
+	}
+}
@@ -27,29 +27,38 @@ func cleanHTML(input string) string {
 }
 
 func GetMainElementHTMLContent(endpoint models.Endpoint, maxElements int) (string, error) {
+	releaseRun, err := acquireEndpointRun(context.Background())
+	if err != nil {
+		return "", err
+	}
+	defer releaseRun()
+
 	browser := GetBrowser()
 
 	scrapeType := GetScrapeType(endpoint)
-	elementToWaitFor := endpoint.MainElementSelector
+	elementToWaitFor := mainElementSelector(endpoint)
 	if scrapeType == PureDetails {
-		elementToWaitFor = endpoint.DetailedViewMainElementSelector
+		elementToWaitFor = detailMainElementSelector(endpoint)
 	}
 
 	fmt.Printf("Element selector: %v\n", elementToWaitFor)
 
 	fmt.Println("Scrape type: ", GetScrapeType(endpoint))
-	page, err := GetStealthPage(context.Background(), browser, endpoint.URL, elementToWaitFor)
+	fetcher := GetFetcher(endpoint, browser)
+	doc, err := fetcher.Fetch(context.Background(), endpoint.URL, elementToWaitFor, mainPageActions(endpoint))
 	if err != nil {
 		return "", err
 	}
 
-	defer page.Close()
+	defer doc.Close()
 
 	// scroll to the bottom of the page
-	SlowScrollToHalf(page)
-	page.MustWaitLoad().MustWaitStable()
+	if browserDoc, ok := doc.(*browserDocument); ok {
+		SlowScrollToHalf(browserDoc.page)
+		browserDoc.page.MustWaitLoad().MustWaitStable()
+	}
 
-	elems, err := getMainElements(page, endpoint, GetScrapeType(endpoint), maxElements)
+	elems, err := getMainElements(doc, fetcher, endpoint, GetScrapeType(endpoint), maxElements)
 	fmt.Printf("Found %v elements\n", len(elems))
 	if err != nil {
 		return "", err
@@ -63,7 +72,7 @@ func GetMainElementHTMLContent(endpoint models.Endpoint, maxElements int) (strin
 	}()
 	html := ""
 	for idx, elem := range elems {
-		html += elem.Element.MustHTML()
+		html += elem.Element.HTML()
 		if idx >= maxElements {
 			break
 		}
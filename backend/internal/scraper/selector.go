@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"scrapeit/internal/models"
+	"strings"
+)
+
+// Selector is a selector together with the language it is written in.
+type Selector struct {
+	Value    string
+	Language models.SelectorLanguage
+}
+
+// CSS returns a css selector.
+func CSS(value string) Selector {
+	return Selector{Value: value, Language: models.SelectorLanguageCSS}
+}
+
+// IsXPath reports whether the selector is an XPath expression.
+func (s Selector) IsXPath() bool {
+	return s.Language == models.SelectorLanguageXPath
+}
+
+// IsEmpty reports whether there is no selector to query.
+func (s Selector) IsEmpty() bool {
+	return strings.TrimSpace(s.Value) == ""
+}
+
+func (s Selector) String() string {
+	if s.IsXPath() {
+		return "xpath:" + s.Value
+	}
+	return s.Value
+}
+
+func mainElementSelector(endpoint models.Endpoint) Selector {
+	return Selector{Value: endpoint.MainElementSelector, Language: endpoint.MainElementSelectorLanguage}
+}
+
+func detailTriggerSelector(endpoint models.Endpoint) Selector {
+	return Selector{Value: endpoint.DetailedViewTriggerSelector, Language: endpoint.DetailedViewTriggerLanguage}
+}
+
+func detailMainElementSelector(endpoint models.Endpoint) Selector {
+	return Selector{Value: endpoint.DetailedViewMainElementSelector, Language: endpoint.DetailedViewMainElementLanguage}
+}
+
+func fieldSelector(selector models.FieldSelector) Selector {
+	return Selector{Value: selector.Selector, Language: selector.SelectorLanguage}
+}
+
+func navigationTriggerSelector(step models.NavigationStep) Selector {
+	return Selector{Value: step.TriggerSelector, Language: step.TriggerSelectorLanguage}
+}
+
+func navigationMainElementSelector(step models.NavigationStep) Selector {
+	return Selector{Value: step.MainElementSelector, Language: step.MainElementSelectorLanguage}
+}
+
+func nextButtonSelector(config models.PaginationConfig) Selector {
+	return Selector{Value: config.NextButtonSelector, Language: config.NextButtonSelectorLanguage}
+}
+
+func loadMoreSelector(config models.PaginationConfig) Selector {
+	return Selector{Value: config.LoadMoreSelector, Language: config.LoadMoreSelectorLanguage}
+}
@@ -0,0 +1,131 @@
+package scraper
+
+import (
+	"fmt"
+	"scrapeit/internal/helpers"
+	"scrapeit/internal/models"
+	"strconv"
+	"strings"
+)
+
+// applyTransforms runs the transforms on a scraped value in order. List
+// values are transformed item by item, including empty items so default can
+// fill them. Typed numbers are only scaled. It returns the final value and
+// the value after every step.
+func applyTransforms(value interface{}, transforms []models.FieldTransform) (interface{}, []models.TransformStep) {
+	steps := make([]models.TransformStep, 0, len(transforms))
+
+	for _, transform := range transforms {
+		switch v := value.(type) {
+		case string:
+			value = applyTransform(v, transform)
+		case []string:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = applyTransform(item, transform)
+			}
+			value = items
+		case float64:
+			value = applyNumberTransform(v, transform)
+		}
+		steps = append(steps, models.TransformStep{Type: transform.Type, Output: value})
+	}
+
+	return value, steps
+}
+
+func applyTransform(value string, transform models.FieldTransform) string {
+	switch transform.Type {
+	case models.TransformTypeTrim:
+		return strings.Join(strings.Fields(value), " ")
+	case models.TransformTypeReplace:
+		if transform.From == "" {
+			return value
+		}
+		return strings.ReplaceAll(value, transform.From, transform.To)
+	case models.TransformTypeLowercase:
+		return strings.ToLower(value)
+	case models.TransformTypePrefix:
+		return transform.Value + value
+	case models.TransformTypeSuffix:
+		return value + transform.Value
+	case models.TransformTypeSplit:
+		return splitAndPick(value, transform.Separator, transform.Index)
+	case models.TransformTypeMultiply:
+		return scaleNumber(value, transform.Factor)
+	case models.TransformTypeDivide:
+		if transform.Factor == 0 {
+			fmt.Println("Skipping divide transform with factor 0")
+			return value
+		}
+		return scaleNumber(value, 1/transform.Factor)
+	case models.TransformTypeMap:
+		return mapLookup(value, transform.Mapping)
+	case models.TransformTypeDefault:
+		if strings.TrimSpace(value) == "" {
+			return transform.Value
+		}
+		return value
+	default:
+		fmt.Printf("Unknown transform type: %s\n", transform.Type)
+		return value
+	}
+}
+
+// applyNumberTransform scales a typed number, the other transforms work on
+// text and keep it.
+func applyNumberTransform(value float64, transform models.FieldTransform) float64 {
+	switch transform.Type {
+	case models.TransformTypeMultiply:
+		return value * transform.Factor
+	case models.TransformTypeDivide:
+		if transform.Factor == 0 {
+			fmt.Println("Skipping divide transform with factor 0")
+			return value
+		}
+		return value / transform.Factor
+	default:
+		return value
+	}
+}
+
+func splitAndPick(value string, separator string, index int) string {
+	var parts []string
+	if separator == "" {
+		parts = strings.Fields(value)
+	} else {
+		parts = strings.Split(value, separator)
+	}
+
+	if index < 0 {
+		index += len(parts)
+	}
+	if index < 0 || index >= len(parts) {
+		return ""
+	}
+	return strings.TrimSpace(parts[index])
+}
+
+func scaleNumber(value string, factor float64) string {
+	if strings.TrimSpace(value) == "" {
+		return value
+	}
+	number := helpers.CastPriceStringToFloat(value)
+	return strconv.FormatFloat(number*factor, 'f', -1, 64)
+}
+
+// mapLookup returns the mapped value for value, matching keys exactly first
+// and then ignoring case and surrounding whitespace. Unmapped values are
+// kept.
+func mapLookup(value string, mapping map[string]string) string {
+	if mapped, ok := mapping[value]; ok {
+		return mapped
+	}
+	trimmed := strings.TrimSpace(value)
+	for key, mapped := range mapping {
+		if strings.EqualFold(strings.TrimSpace(key), trimmed) {
+			return mapped
+		}
+	}
+	return value
+}
@@ -0,0 +1,158 @@
+package scraper
+
+import (
+	"reflect"
+	"scrapeit/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestApplyTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		transform models.FieldTransform
+		want      string
+	}{
+		{"trim", "  a \n  b\t", models.FieldTransform{Type: models.TransformTypeTrim}, "a b"},
+		{"replace", "1.234 EUR", models.FieldTransform{Type: models.TransformTypeReplace, From: " EUR", To: ""}, "1.234"},
+		{"replace without from", "abc", models.FieldTransform{Type: models.TransformTypeReplace, To: "x"}, "abc"},
+		{"lowercase", "New ArrivaL", models.FieldTransform{Type: models.TransformTypeLowercase}, "new arrival"},
+		{"prefix", "/item/1", models.FieldTransform{Type: models.TransformTypePrefix, Value: "https://example.com"}, "https://example.com/item/1"},
+		{"suffix", "12", models.FieldTransform{Type: models.TransformTypeSuffix, Value: " m²"}, "12 m²"},
+		{"split", "Berlin, Germany", models.FieldTransform{Type: models.TransformTypeSplit, Separator: ",", Index: 1}, "Germany"},
+		{"split on whitespace", "3 rooms available", models.FieldTransform{Type: models.TransformTypeSplit, Index: 0}, "3"},
+		{"split negative index", "a/b/c", models.FieldTransform{Type: models.TransformTypeSplit, Separator: "/", Index: -1}, "c"},
+		{"split out of range", "a/b", models.FieldTransform{Type: models.TransformTypeSplit, Separator: "/", Index: 5}, ""},
+		{"multiply", "12.5", models.FieldTransform{Type: models.TransformTypeMultiply, Factor: 2}, "25"},
+		{"multiply empty", " ", models.FieldTransform{Type: models.TransformTypeMultiply, Factor: 2}, " "},
+		{"divide", "1500", models.FieldTransform{Type: models.TransformTypeDivide, Factor: 1000}, "1.5"},
+		{"divide by zero", "1500", models.FieldTransform{Type: models.TransformTypeDivide}, "1500"},
+		{"map exact", "Y", models.FieldTransform{Type: models.TransformTypeMap, Mapping: map[string]string{"Y": "yes"}}, "yes"},
+		{"map ignoring case", " y ", models.FieldTransform{Type: models.TransformTypeMap, Mapping: map[string]string{"Y": "yes"}}, "yes"},
+		{"map unmapped", "n", models.FieldTransform{Type: models.TransformTypeMap, Mapping: map[string]string{"Y": "yes"}}, "n"},
+		{"default empty", "  ", models.FieldTransform{Type: models.TransformTypeDefault, Value: "n/a"}, "n/a"},
+		{"default set", "x", models.FieldTransform{Type: models.TransformTypeDefault, Value: "n/a"}, "x"},
+		{"unknown", "x", models.FieldTransform{Type: "reverse"}, "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyTransform(tt.value, tt.transform); got != tt.want {
+				t.Errorf("applyTransform(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTransforms(t *testing.T) {
+	chain := []models.FieldTransform{
+		{Type: models.TransformTypeTrim},
+		{Type: models.TransformTypeSplit, Separator: ":", Index: 1},
+		{Type: models.TransformTypeLowercase},
+	}
+
+	tests := []struct {
+		name       string
+		value      interface{}
+		transforms []models.FieldTransform
+		want       interface{}
+		wantSteps  []interface{}
+	}{
+		{
+			name:       "text chain",
+			value:      "  Color:  RED ",
+			transforms: chain,
+			want:       "red",
+			wantSteps:  []interface{}{"Color: RED", "RED", "red"},
+		},
+		{
+			name:       "list items keep empty results",
+			value:      []string{"Size: XL", "no separator", "Fit:Slim"},
+			transforms: chain,
+			want:       []string{"xl", "", "slim"},
+			wantSteps:  []interface{}{[]string{"Size: XL", "no separator", "Fit:Slim"}, []string{"XL", "", "Slim"}, []string{"xl", "", "slim"}},
+		},
+		{
+			name:       "default fills empty list items",
+			value:      []string{"red", " ", "blue"},
+			transforms: []models.FieldTransform{{Type: models.TransformTypeTrim}, {Type: models.TransformTypeDefault, Value: "unknown"}},
+			want:       []string{"red", "unknown", "blue"},
+			wantSteps:  []interface{}{[]string{"red", "", "blue"}, []string{"red", "unknown", "blue"}},
+		},
+		{
+			name:       "no transforms",
+			value:      "x",
+			transforms: nil,
+			want:       "x",
+			wantSteps:  []interface{}{},
+		},
+		{
+			name:       "typed numbers are scaled",
+			value:      4125.0,
+			transforms: []models.FieldTransform{{Type: models.TransformTypeDivide, Factor: 1000}, {Type: models.TransformTypeMultiply, Factor: 2}},
+			want:       8.25,
+			wantSteps:  []interface{}{4.125, 8.25},
+		},
+		{
+			name:       "non text values are kept",
+			value:      12.0,
+			transforms: []models.FieldTransform{{Type: models.TransformTypeSuffix, Value: "x"}},
+			want:       12.0,
+			wantSteps:  []interface{}{12.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, steps := applyTransforms(tt.value, tt.transforms)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyTransforms() = %#v, want %#v", got, tt.want)
+			}
+			outputs := make([]interface{}, len(steps))
+			for i, step := range steps {
+				if step.Type != tt.transforms[i].Type {
+					t.Errorf("step %d type = %s, want %s", i, step.Type, tt.transforms[i].Type)
+				}
+				outputs[i] = step.Output
+			}
+			if !reflect.DeepEqual(outputs, tt.wantSteps) {
+				t.Errorf("step outputs = %#v, want %#v", outputs, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestListFieldTransforms(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<ul><li>Red</li><li> </li><li>Blue</li></ul>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	element := newHTMLElement(doc.Selection)
+	fields := []models.Field{{ID: "colors", Type: models.FieldTypeList}}
+
+	tests := []struct {
+		name       string
+		transforms []models.FieldTransform
+		want       []string
+	}{
+		{"empty items are dropped", nil, []string{"Red", "Blue"}},
+		{"default fills empty items", []models.FieldTransform{{Type: models.TransformTypeDefault, Value: "n/a"}}, []string{"Red", "n/a", "Blue"}},
+		{"items emptied by a transform are dropped", []models.FieldTransform{{Type: models.TransformTypeReplace, From: "Red", To: ""}}, []string{"Blue"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := models.FieldSelector{FieldID: "colors", Selector: "li", Transforms: tt.transforms}
+			details, err := getElementDetails(element, []models.FieldSelector{selector}, fields, nil)
+			if err != nil {
+				t.Fatalf("getElementDetails() error: %v", err)
+			}
+			if got := details[0].Value; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}